@@ -1,73 +1,341 @@
 package cmd
 
 import (
+	"bufio"
+	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"log/slog"
+	"maps"
 	"net/url"
+	"os"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/the20100/meta-ad-library-cli/internal/api"
 	"github.com/the20100/meta-ad-library-cli/internal/output"
 )
 
-const adDetailFields = "id,ad_creation_time,ad_delivery_start_time,ad_delivery_stop_time," +
-	"ad_creative_bodies,ad_creative_image_urls,ad_creative_link_captions," +
-	"ad_creative_link_descriptions,ad_creative_link_titles," +
-	"ad_snapshot_url,page_id,page_name,publisher_platforms,languages," +
-	"spend,impressions,currency,bylines," +
-	"region_distribution,demographic_distribution"
+// distributionBarWidth is the length, in cells, of the longest distribution bar.
+const distributionBarWidth = 30
 
 var adCmd = &cobra.Command{
 	Use:   "ad",
 	Short: "Get details about a specific ad",
 }
 
+// adGetAttempts is how many times a bulk lookup is tried when Meta reports a rate limit.
+const adGetAttempts = 3
+
 var adGetCmd = &cobra.Command{
-	Use:   "get <ad_archive_id>",
-	Short: "Get detailed info for a specific ad by its archive ID",
-	Long: `Fetches details for a single ad by its archive ID from the Ad Library.
+	Use:   "get <ad_archive_id> [ad_archive_id...]",
+	Short: "Get detailed info for one or more ads by archive ID",
+	Long: `Fetches details for ads by their archive ID from the Ad Library.
 
 The ad archive ID can be found in search results (the "id" field) or in the
 ad_snapshot_url URL parameter.
 
+With a single ID the detail view is printed. Ads with several creative
+variants (bodies, link titles, ...) list each variant on its own line under
+a heading.
+
+With several IDs (as arguments or one per line in --ids-file), ads are fetched
+by --concurrency workers (a global flag, default 4) and printed as a combined
+table, or a JSON array in input order. Lookups that hit a rate limit are
+retried with backoff; IDs that still fail are reported on stderr and the
+command exits non-zero.
+
+--raw prints the response body for a single ad exactly as Meta sent it,
+indented but otherwise untouched: every key is kept, including ones the
+tool doesn't model, and --select, --canonical and derived fields don't
+apply. Use it to check which fields Meta actually returns.
+
+--history adds the ad's delivery timeline (start, stop, days delivered) and
+the delivery breakdowns Meta publishes for EU and political/issue ads:
+delivery by region, and EU reach in total, by country and by age and gender.
+The Ad Library has no daily spend or delivery series, so this is as much of
+an ad's history as the API exposes.
+
 Examples:
   meta-adlib ad get 123456789012345
-  meta-adlib ad get 123456789012345 --json`,
-	Args: cobra.ExactArgs(1),
+  meta-adlib ad get 123456789012345 --json
+  meta-adlib ad get 123456789012345 --fields id,spend,impressions
+  meta-adlib ad get 123456789012345 --all-fields --raw
+  meta-adlib ad get 123456789012345 --history
+  meta-adlib ad get 111 222 333
+  meta-adlib ad get --ids-file ids.txt --concurrency 8 --json`,
 	RunE: runAdGet,
 }
 
+var (
+	adGetFields    string
+	adGetAllFields bool
+	adGetDryRun    bool
+	adGetRawParams []string
+	adGetIDsFile   string
+	adGetRaw       bool
+)
+
 func init() {
+	adGetCmd.Flags().StringVar(&adGetFields, "fields", api.FieldsDetail, "Comma-separated list of fields to return")
+	adGetCmd.Flags().BoolVar(&adGetAllFields, "all-fields", false, "Request every documented ad field")
+	adGetCmd.MarkFlagsMutuallyExclusive("fields", "all-fields")
+	adGetCmd.Flags().StringArrayVar(&adGetRawParams, "param", nil, "Raw Graph API query parameter as key=value, overriding built-in ones. Repeatable.")
+	adGetCmd.Flags().BoolVar(&adGetDryRun, "dry-run", false, "Print the request URL (token redacted) without calling the API")
+	adGetCmd.Flags().StringVar(&adGetIDsFile, "ids-file", "", "File with one ad archive ID per line (- for stdin)")
+	addWithTokenFlag(adGetCmd.Flags())
+	adGetCmd.Flags().StringSliceVar(&columnsFlag, "columns", nil, "Comma-separated table columns for several IDs (see search --help); derived ones like spend_per_impression are also added to JSON")
+	adGetCmd.Flags().StringVar(&convertToFlag, "convert-to", "", "Convert spend to this currency (e.g. USD) with approximate exchange rates (see --fx-source), for display and totals")
+	addFXFlags(adGetCmd.Flags())
+	addSpendTierFlags(adGetCmd.Flags())
+	adGetCmd.Flags().BoolVar(&adGetRaw, "raw", false, "Print Meta's response body verbatim (indented), with every key it sent")
+	adGetCmd.MarkFlagsMutuallyExclusive("raw", "dry-run")
+	adGetCmd.MarkFlagsMutuallyExclusive("raw", "ids-file")
+
 	adCmd.AddCommand(adGetCmd)
 	rootCmd.AddCommand(adCmd)
 }
 
 func runAdGet(cmd *cobra.Command, args []string) error {
-	id := args[0]
+	ids := args
+	if adGetIDsFile != "" {
+		fileIDs, err := readIDsFile(adGetIDsFile)
+		if err != nil {
+			return err
+		}
+		ids = append(ids, fileIDs...)
+	}
+	if len(ids) == 0 {
+		return usageErrorf("at least one ad archive ID is required (as an argument or via --ids-file)")
+	}
+	fields := adGetFields
+	if adGetAllFields {
+		fields = api.FieldsAll
+	}
+	if adGetHistory {
+		if len(ids) != 1 || adGetIDsFile != "" {
+			return usageErrorf("--history takes a single ad archive ID")
+		}
+		fields = withHistoryFields(fields)
+	}
+	if err := checkColumns(); err != nil {
+		return err
+	}
+	if err := checkConvertTo(); err != nil {
+		return err
+	}
+	if err := checkSpendTiers(); err != nil {
+		return err
+	}
+	if spendTiers != nil {
+		fields = withField(withField(fields, "spend"), "currency")
+	}
+	if err := checkFields(fields); err != nil {
+		return err
+	}
+	fieldList := strings.Split(fields, ",")
+	extra := url.Values{}
+	if err := applyRawParams(extra, adGetRawParams); err != nil {
+		return err
+	}
 
-	params := url.Values{}
-	params.Set("fields", adDetailFields)
+	if adGetDryRun {
+		params := url.Values{"fields": {fields}}
+		for k, vs := range extra {
+			params[k] = vs
+		}
+		for _, id := range ids {
+			if err := printDryRun(client.RequestURL("/"+id, params)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
 
-	body, err := client.Get("/"+id, params)
+	if adGetRaw {
+		if len(ids) != 1 {
+			return usageErrorf("--raw takes a single ad archive ID")
+		}
+		return printRawAd(ids[0], fieldList, extra)
+	}
+	if len(ids) == 1 && adGetIDsFile == "" {
+		return printSingleAd(cmd, ids[0], fieldList, extra)
+	}
+
+	bodies, errs := fetchAds(ids, fieldList, extra, concurrencyFlag)
+
+	var raw []json.RawMessage
+	failed := 0
+	for i, id := range ids {
+		if errs[i] != nil {
+			slog.Warn(fmt.Sprintf("ad %s: %v", id, errs[i]))
+			failed++
+			continue
+		}
+		raw = append(raw, bodies[i])
+	}
+	raw = filterSpendTiers(raw)
+
+	if output.IsJSON(cmd) {
+		raw, err := adsJSON(raw)
+		if err != nil {
+			return err
+		}
+		if raw == nil {
+			raw = []json.RawMessage{}
+		}
+		if err := output.PrintJSON(raw, output.IsPretty(cmd)); err != nil {
+			return err
+		}
+	} else if len(raw) > 0 {
+		ads := parseAds(raw)
+		if f := extra.Get("fields"); f != "" {
+			fields = f
+		}
+		noteUnrequestedColumns(fields)
+		printAdsTable(ads, nil)
+		fmt.Printf("\n%d of %d ad(s) fetched\n", len(ads), len(ids))
+		printAdsSummary(ads)
+	}
+
+	if failed > 0 {
+		// Rate-limit failures keep their exit code when every lookup failed the same way.
+		if failed == len(ids) {
+			return errs[0]
+		}
+		return fmt.Errorf("%d of %d ad(s) could not be fetched", failed, len(ids))
+	}
+	return nil
+}
+
+// printSingleAd fetches one ad and prints it as raw JSON or the detail view.
+func printSingleAd(cmd *cobra.Command, id string, fields []string, extra url.Values) error {
+	a, body, err := client.GetAd(id, fields, extra)
 	if err != nil {
 		return err
 	}
-
-	var a api.AdArchiveRecord
-	if err := json.Unmarshal(body, &a); err != nil {
-		return fmt.Errorf("parsing ad: %w", err)
+	if len(filterSpendTiers([]json.RawMessage{body})) == 0 {
+		slog.Info(fmt.Sprintf("ad %s is in none of the --spend-tier tiers", id))
+		if output.IsJSON(cmd) {
+			fmt.Fprintln(output.Out, "null")
+		}
+		return nil
 	}
 
 	if output.IsJSON(cmd) {
-		return output.PrintJSON(json.RawMessage(body), output.IsPretty(cmd))
+		raw, err := adsJSON([]json.RawMessage{body})
+		if err != nil {
+			return err
+		}
+		return output.PrintJSON(raw[0], output.IsPretty(cmd))
 	}
 
-	printAdDetail(a)
+	writeAdDetail(output.Out, *a)
+	if adGetHistory {
+		writeDeliveryHistory(output.Out, *a)
+	}
 	return nil
 }
 
-func printAdDetail(a api.AdArchiveRecord) {
+// printRawAd fetches one ad and prints the response body as Meta sent it,
+// only indented. It calls Get rather than GetAd, which would reject a body
+// that doesn't decode into an AdArchiveRecord: --raw is for seeing such
+// bodies.
+func printRawAd(id string, fields []string, extra url.Values) error {
+	params := url.Values{}
+	if len(fields) > 0 {
+		params.Set("fields", strings.Join(fields, ","))
+	}
+	maps.Copy(params, extra)
+	body, err := client.Get("/"+id, params)
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, body, "", "  "); err != nil {
+		return fmt.Errorf("parsing response: %w", err)
+	}
+	buf.WriteByte('\n')
+	_, err = buf.WriteTo(output.Out)
+	return err
+}
+
+// fetchAds looks up every id with the given number of workers. Results and
+// errors are indexed like ids.
+func fetchAds(ids, fields []string, extra url.Values, workers int) ([]json.RawMessage, []error) {
+	bodies := make([]json.RawMessage, len(ids))
+	errs := make([]error, len(ids))
+	var t throttle
+	runPool(len(ids), workers, func(i int) {
+		bodies[i], errs[i] = fetchAdWithRetry(&t, ids[i], fields, extra)
+	})
+	return bodies, errs
+}
+
+// fetchAdWithRetry gets a single ad, backing off and retrying when Meta
+// reports a rate limit. The backoff pauses every worker sharing t.
+func fetchAdWithRetry(t *throttle, id string, fields []string, extra url.Values) (json.RawMessage, error) {
+	backoff := 5 * time.Second
+	for attempt := 1; ; attempt++ {
+		t.wait()
+		_, body, err := client.GetAd(id, fields, extra)
+		var metaErr *api.MetaError
+		if err == nil || attempt == adGetAttempts || !errors.As(err, &metaErr) || !metaErr.IsRateLimit() {
+			return body, err
+		}
+		slog.Info("rate limited, retrying", "ad", id, "attempt", attempt+1, "in", backoff)
+		t.pause(backoff)
+		backoff *= 2
+	}
+}
+
+// readIDsFile reads one ID per line from path ("-" for stdin), skipping blank
+// lines and # comments.
+func readIDsFile(path string) ([]string, error) {
+	var r io.Reader = os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		r = f
+	}
+
+	var ids []string
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		ids = append(ids, line)
+	}
+	return ids, sc.Err()
+}
+
+// writeVariants prints values under a "label:" heading, one numbered entry
+// per value, indenting the continuation lines of multi-line copy.
+func writeVariants(w io.Writer, label string, values []string) {
+	fmt.Fprintf(w, "\n%s:\n", label)
+	for i, v := range values {
+		prefix := fmt.Sprintf("  [%d] ", i+1)
+		indent := strings.Repeat(" ", len(prefix))
+		for j, line := range strings.Split(strings.TrimRight(v, "\n"), "\n") {
+			if j == 0 {
+				fmt.Fprintf(w, "%s%s\n", prefix, line)
+			} else {
+				fmt.Fprintf(w, "%s%s\n", indent, line)
+			}
+		}
+	}
+}
+
+// writeAdDetail renders the full detail view of an ad to w.
+func writeAdDetail(w io.Writer, a api.AdArchiveRecord) {
 	status := "inactive"
 	if a.AdDeliveryStopTime == "" {
 		status = "active"
@@ -75,7 +343,7 @@ func printAdDetail(a api.AdArchiveRecord) {
 
 	spend := "-"
 	if a.Spend != nil {
-		spend = a.Spend.String()
+		spend = rangeCell(a.Spend)
 		if a.Currency != "" {
 			spend += " " + a.Currency
 		}
@@ -83,7 +351,7 @@ func printAdDetail(a api.AdArchiveRecord) {
 
 	impr := "-"
 	if a.Impressions != nil {
-		impr = a.Impressions.String()
+		impr = rangeCell(a.Impressions)
 	}
 
 	rows := [][]string{
@@ -96,40 +364,101 @@ func printAdDetail(a api.AdArchiveRecord) {
 		{"Platforms", output.JoinStrings(a.PublisherPlatforms, ", ")},
 		{"Languages", output.JoinStrings(a.Languages, ", ")},
 		{"Bylines", a.Bylines},
+		{"Target Ages", formatTargetAges(a.TargetAges)},
+		{"Target Gender", a.TargetGender},
+		{"Target Locations", formatTargetLocations(a.TargetLocations)},
 		{"Spend (est.)", spend},
 		{"Impressions (est.)", impr},
-		{"Snapshot URL", a.AdSnapshotURL},
 	}
+	rows = append(rows, []string{"Ad Library", output.Hyperlink(a.Permalink(), a.Permalink())}, snapshotRow(a))
 
-	if len(a.AdCreativeBodies) > 0 {
-		rows = append(rows, []string{"Body", strings.Join(a.AdCreativeBodies, " | ")})
+	// Creative variants: a single value fits the table; several get their own
+	// section below it, one variant per line.
+	links := make([]string, len(a.AdCreativeImageURLs))
+	for i, u := range a.AdCreativeImageURLs {
+		links[i] = output.Hyperlink(u, u)
 	}
-	if len(a.AdCreativeLinkTitles) > 0 {
-		rows = append(rows, []string{"Link Title", strings.Join(a.AdCreativeLinkTitles, " | ")})
+	creatives := []struct {
+		label  string
+		values []string
+	}{
+		{"Body", a.AdCreativeBodies},
+		{"Link Title", a.AdCreativeLinkTitles},
+		{"Link Description", a.AdCreativeLinkDescriptions},
+		{"Link Caption", a.AdCreativeLinkCaptions},
+		{"Image URLs", links},
 	}
-	if len(a.AdCreativeLinkDescriptions) > 0 {
-		rows = append(rows, []string{"Link Description", strings.Join(a.AdCreativeLinkDescriptions, " | ")})
-	}
-	if len(a.AdCreativeLinkCaptions) > 0 {
-		rows = append(rows, []string{"Link Caption", strings.Join(a.AdCreativeLinkCaptions, " | ")})
+	for _, c := range creatives {
+		if len(c.values) == 1 && !strings.Contains(c.values[0], "\n") {
+			rows = append(rows, []string{c.label, c.values[0]})
+		}
 	}
-	if len(a.AdCreativeImageURLs) > 0 {
-		rows = append(rows, []string{"Image URLs", strings.Join(a.AdCreativeImageURLs, "\n")})
+
+	output.FprintKeyValue(w, rows)
+
+	for _, c := range creatives {
+		if len(c.values) > 1 || (len(c.values) == 1 && strings.Contains(c.values[0], "\n")) {
+			writeVariants(w, c.label, c.values)
+		}
 	}
 
-	output.PrintKeyValue(rows)
+	bars := output.IsTerminal()
 
 	if len(a.RegionDistribution) > 0 {
-		fmt.Println("\nRegion Distribution:")
+		fmt.Fprintln(w, "\nRegion Distribution:")
+		peak := 0.0
+		for _, d := range a.RegionDistribution {
+			peak = max(peak, d.Percentage)
+		}
 		for _, d := range a.RegionDistribution {
-			fmt.Printf("  %-30s %.1f%%\n", d.Region, d.Percentage)
+			if bars {
+				fmt.Fprintf(w, "  %-30s %-30s %.1f%%\n", d.Region, output.Bar(d.Percentage, peak, distributionBarWidth), d.Percentage)
+			} else {
+				fmt.Fprintf(w, "  %-30s %.1f%%\n", d.Region, d.Percentage)
+			}
 		}
 	}
 
 	if len(a.DemographicDistribution) > 0 {
-		fmt.Println("\nDemographic Distribution:")
+		fmt.Fprintln(w, "\nDemographic Distribution:")
+		peak := 0.0
+		for _, d := range a.DemographicDistribution {
+			peak = max(peak, d.Percentage)
+		}
 		for _, d := range a.DemographicDistribution {
-			fmt.Printf("  %-5s %-10s %.1f%%\n", d.Gender, d.Age, d.Percentage)
+			if bars {
+				fmt.Fprintf(w, "  %-7s %-10s %-30s %.1f%%\n", d.Gender, d.Age, output.Bar(d.Percentage, peak, distributionBarWidth), d.Percentage)
+			} else {
+				fmt.Fprintf(w, "  %-5s %-10s %.1f%%\n", d.Gender, d.Age, d.Percentage)
+			}
+		}
+	}
+}
+
+// formatTargetAges renders Meta's ["min","max"] pair as "min–max".
+func formatTargetAges(ages []string) string {
+	if len(ages) == 2 {
+		return ages[0] + "–" + ages[1]
+	}
+	return strings.Join(ages, ", ")
+}
+
+// formatTargetLocations renders locations as "Name (type)", marking exclusions
+// and locations Meta obfuscated for privacy.
+func formatTargetLocations(locs []api.TargetLocation) string {
+	parts := make([]string, 0, len(locs))
+	for _, l := range locs {
+		p := l.Name
+		if l.Type != "" {
+			p += " (" + l.Type + ")"
+		}
+		if l.Excluded {
+			p = "excl. " + p
+		}
+		if l.NumObfuscated > 0 {
+			p += fmt.Sprintf(" +%d hidden", l.NumObfuscated)
 		}
+		parts = append(parts, p)
 	}
+	return strings.Join(parts, ", ")
 }
@@ -1,23 +1,45 @@
 package cmd
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
 	"net/url"
 	"os"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/the20100/meta-ad-library-cli/internal/api"
 	"github.com/the20100/meta-ad-library-cli/internal/config"
+	"github.com/the20100/meta-ad-library-cli/internal/logging"
+	"github.com/the20100/meta-ad-library-cli/internal/output"
 )
 
 const (
 	metaMeURL       = "https://graph.facebook.com/v23.0/me"
 	metaExchangeURL = "https://graph.facebook.com/v23.0/oauth/access_token"
+	metaDebugURL    = "https://graph.facebook.com/v23.0/debug_token"
+
+	// authAttempts bounds retries of auth calls on network errors and 5xx.
+	authAttempts = 3
+
+	// clockSkewWarnAt is how far the local clock may drift from Meta's before
+	// expiry dates are considered unreliable.
+	clockSkewWarnAt = 5 * time.Minute
 )
 
+// adLibraryScopes are the permissions a token needs for Ad Library queries.
+var adLibraryScopes = []string{"ads_read"}
+
+// authHTTPClient is used for /me and token exchange calls.
+var authHTTPClient = &http.Client{Timeout: 15 * time.Second}
+
 var authSetTokenNoExtend bool
 var authExtendTokenSave bool
 
@@ -38,8 +60,12 @@ are set (env vars), the token is automatically upgraded to a long-lived token
 You can obtain a short-lived token from:
   • Meta Graph API Explorer: https://developers.facebook.com/tools/explorer/
 
+The token is stored in the active profile; pass --profile <name> to save it
+under another name (e.g. one per client account).
+
 Examples:
   meta-adlib auth set-token EAABsbCS...
+  meta-adlib auth set-token EAABsbCS... --profile client-a
   meta-adlib auth set-token EAABsbCS... --no-extend
   META_APP_ID=123 META_APP_SECRET=abc meta-adlib auth set-token EAABsbCS...`,
 	Args: cobra.ExactArgs(1),
@@ -84,59 +110,238 @@ Examples:
 }
 
 var authLogoutCmd = &cobra.Command{
-	Use:   "logout",
-	Short: "Remove saved credentials",
+	Use:   "logout [profile]",
+	Short: "Remove saved credentials (the active profile, or the named one)",
+	Args:  cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		if err := config.Clear(); err != nil {
+		name := activeProfile()
+		if len(args) == 1 {
+			name = args[0]
+		}
+		if err := config.RemoveProfile(name); err != nil {
 			return fmt.Errorf("failed to clear config: %w", err)
 		}
-		fmt.Println("logged out")
+		fmt.Printf("logged out of profile %s\n", name)
 		return nil
 	},
 }
 
-var authStatusCmd = &cobra.Command{
-	Use:   "status",
-	Short: "Show current authentication status",
+var authProfilesCmd = &cobra.Command{
+	Use:   "profiles",
+	Short: "List saved profiles with their user and expiry",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		c, err := config.Load()
+		f, err := config.LoadFile()
 		if err != nil {
 			return fmt.Errorf("failed to load config: %w", err)
 		}
-		if c.AccessToken == "" {
-			fmt.Println("not authenticated")
-			fmt.Println("  → meta-adlib auth set-token <token>")
-			fmt.Println("  → export META_ADLIB_TOKEN=<token>")
+		if len(f.Profiles) == 0 {
+			fmt.Println("no saved profiles")
+			fmt.Println("  → meta-adlib auth set-token <token> [--profile <name>]")
 			return nil
 		}
 
-		fmt.Printf("authenticated as %s (ID: %s)\n", c.UserName, c.UserID)
-
-		days := c.DaysUntilExpiry()
-		switch {
-		case days == -1:
-			fmt.Println("  expires:  unknown (token may never expire, or expiry not tracked)")
-		case c.IsExpired():
-			fmt.Printf("  expires:  EXPIRED on %s — run: meta-adlib auth refresh\n",
-				c.ExpiresAt().Format("2006-01-02"))
-		case days <= 7:
-			fmt.Printf("  expires:  %s (%d day(s) left) ⚠️  — run: meta-adlib auth refresh\n",
-				c.ExpiresAt().Format("2006-01-02"), days)
-		default:
-			fmt.Printf("  expires:  %s (%d days left)\n",
-				c.ExpiresAt().Format("2006-01-02"), days)
+		active := f.Active()
+		headers := []string{"", "PROFILE", "USER", "USER ID", "EXPIRES"}
+		rows := make([][]string, 0, len(f.Profiles))
+		for _, name := range f.Names() {
+			c := f.Profiles[name]
+			marker := ""
+			if name == active {
+				marker = "*"
+			}
+			expires := "unknown"
+			switch {
+			case c.TokenExpiresAt == 0:
+			case c.IsExpired():
+				expires = "EXPIRED " + c.ExpiresAt().Format("2006-01-02")
+			default:
+				expires = fmt.Sprintf("%s (%d days)", c.ExpiresAt().Format("2006-01-02"), c.DaysUntilExpiry())
+			}
+			rows = append(rows, []string{marker, name, orDash(c.UserName), orDash(c.UserID), expires})
 		}
+		output.PrintTable(headers, rows)
+		return nil
+	},
+}
 
-		fmt.Printf("  config:   %s\n", config.Path())
+var authUseCmd = &cobra.Command{
+	Use:   "use <profile>",
+	Short: "Set the active profile",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := config.Use(args[0]); err != nil {
+			return fmt.Errorf("%w — list profiles with: meta-adlib auth profiles", err)
+		}
+		fmt.Printf("active profile: %s\n", args[0])
 		return nil
 	},
 }
 
+var authWhoamiCmd = &cobra.Command{
+	Use:   "whoami",
+	Short: "Check the current token live and show whose it is",
+	Long: `Calls /me with the token other commands would use (same resolution order:
+--token, META_TOKEN, own config, meta-auth) and prints the user it belongs
+to. Nothing is read from or written to the cached profile data, so a
+success means the token works right now.
+
+A rejected token exits with code 2.
+
+Examples:
+  meta-adlib auth whoami
+  meta-adlib auth whoami --json`,
+	Args: cobra.NoArgs,
+	RunE: runAuthWhoami,
+}
+
+// whoami is the --json shape of auth whoami.
+type whoami struct {
+	UserID   string `json:"user_id"`
+	UserName string `json:"user_name"`
+}
+
+func runAuthWhoami(cmd *cobra.Command, args []string) error {
+	token, err := resolveToken()
+	if err != nil {
+		return err
+	}
+	id, name, err := fetchMe(token)
+	if err != nil {
+		return err
+	}
+	if output.IsJSON(cmd) {
+		return output.PrintJSON(whoami{UserID: id, UserName: name}, output.IsPretty(cmd))
+	}
+	fmt.Printf("%s (ID: %s)\n", orDash(name), id)
+	return nil
+}
+
+var authCheckVerbose bool
+
+var authCheckCmd = &cobra.Command{
+	Use:   "check",
+	Short: "Exit non-zero unless the current token works (for CI)",
+	Long: `Makes one live /me call with the token other commands would use and exits
+0 if it works, printing nothing (--verbose names the user). Otherwise the
+reason is printed on stderr and the exit code says why: 2 when there is no
+token or Meta rejects it (expired, revoked, invalid), 1 when Meta could not be
+reached.
+
+Examples:
+  meta-adlib auth check && meta-adlib search --query "climate" --country FR
+  meta-adlib auth check --verbose`,
+	Args: cobra.NoArgs,
+	RunE: runAuthCheck,
+}
+
+func runAuthCheck(cmd *cobra.Command, args []string) error {
+	token, err := resolveToken()
+	if err != nil {
+		return err
+	}
+	id, name, err := fetchMe(token)
+	var metaErr *api.MetaError
+	switch {
+	case errors.As(err, &metaErr) && metaErr.IsAuth():
+		return authError{fmt.Errorf("token rejected by Meta (expired, revoked, or invalid): %w", err)}
+	case err != nil:
+		return fmt.Errorf("could not check the token: %w", err)
+	}
+	if authCheckVerbose {
+		fmt.Printf("token OK: %s (ID: %s)\n", orDash(name), id)
+	}
+	return nil
+}
+
+var authStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show current authentication status",
+	Long: `Shows the active profile's user, token expiry, and granted scopes.
+
+With --json, prints an object for monitoring scripts:
+  {"authenticated", "profile", "user_id", "user_name", "expires_at",
+   "days_until_expiry", "expired", "scopes"}
+expires_at and days_until_expiry are null when the expiry is unknown.
+
+Examples:
+  meta-adlib auth status
+  meta-adlib auth status --json`,
+	RunE: runAuthStatus,
+}
+
+// authStatus is the --json shape of auth status.
+type authStatus struct {
+	Authenticated   bool     `json:"authenticated"`
+	Profile         string   `json:"profile"`
+	UserID          string   `json:"user_id,omitempty"`
+	UserName        string   `json:"user_name,omitempty"`
+	ExpiresAt       *string  `json:"expires_at"`
+	DaysUntilExpiry *int     `json:"days_until_expiry"`
+	Expired         bool     `json:"expired"`
+	Scopes          []string `json:"scopes,omitempty"`
+}
+
+func runAuthStatus(cmd *cobra.Command, args []string) error {
+	c, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	if output.IsJSON(cmd) {
+		st := authStatus{
+			Authenticated: c.AccessToken != "",
+			Profile:       activeProfile(),
+			UserID:        c.UserID,
+			UserName:      c.UserName,
+			Expired:       c.IsExpired(),
+			Scopes:        c.Scopes,
+		}
+		if c.TokenExpiresAt != 0 {
+			exp := c.ExpiresAt().UTC().Format(time.RFC3339)
+			days := c.DaysUntilExpiry()
+			st.ExpiresAt, st.DaysUntilExpiry = &exp, &days
+		}
+		return output.PrintJSON(st, output.IsPretty(cmd))
+	}
+
+	if c.AccessToken == "" {
+		fmt.Println("not authenticated")
+		fmt.Println("  → meta-adlib auth set-token <token>")
+		fmt.Println("  → export META_ADLIB_TOKEN=<token>")
+		return nil
+	}
+
+	fmt.Printf("authenticated as %s (ID: %s)\n", c.UserName, c.UserID)
+	fmt.Printf("  profile:  %s\n", activeProfile())
+
+	days := c.DaysUntilExpiry()
+	switch {
+	case days == -1:
+		fmt.Println("  expires:  unknown (token may never expire, or expiry not tracked)")
+	case c.IsExpired():
+		fmt.Printf("  expires:  EXPIRED on %s — run: meta-adlib auth refresh\n",
+			c.ExpiresAt().Format("2006-01-02"))
+	case days <= 7:
+		fmt.Printf("  expires:  %s (%d day(s) left) ⚠️  — run: meta-adlib auth refresh\n",
+			c.ExpiresAt().Format("2006-01-02"), days)
+	default:
+		fmt.Printf("  expires:  %s (%d days left)\n",
+			c.ExpiresAt().Format("2006-01-02"), days)
+	}
+
+	printScopes("  scopes:   ", c.Scopes)
+	fmt.Printf("  config:   %s\n", config.Path())
+	warnClockSkew()
+	return nil
+}
+
 func init() {
 	authSetTokenCmd.Flags().BoolVar(&authSetTokenNoExtend, "no-extend", false, "Skip upgrading to long-lived token even if app credentials are available")
+	authCheckCmd.Flags().BoolVar(&authCheckVerbose, "verbose", false, "Print the token's user on success")
 	authExtendTokenCmd.Flags().BoolVar(&authExtendTokenSave, "save", false, "Save the long-lived token to config (replaces current token)")
 
-	authCmd.AddCommand(authSetTokenCmd, authExtendTokenCmd, authRefreshCmd, authLogoutCmd, authStatusCmd)
+	authCmd.AddCommand(authSetTokenCmd, authExtendTokenCmd, authRefreshCmd, authLogoutCmd, authStatusCmd,
+		authWhoamiCmd, authCheckCmd, authProfilesCmd, authUseCmd)
 	rootCmd.AddCommand(authCmd)
 }
 
@@ -156,16 +361,14 @@ func runAuthSetToken(cmd *cobra.Command, args []string) error {
 		fmt.Println("app credentials found — upgrading to long-lived token (~60 days)...")
 		lt, exp, err := exchangeToLongLived(token, appID, appSecret)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "warning: could not upgrade to long-lived token: %v\n", err)
-			fmt.Fprintf(os.Stderr, "         saving original token. Use --no-extend to suppress this warning.\n")
+			slog.Warn(fmt.Sprintf("could not upgrade to long-lived token: %v — saving original token (use --no-extend to suppress this warning)", err))
 		} else {
 			finalToken = lt
 			expiresAt = exp
 			fmt.Println("token upgraded to long-lived")
 		}
 	} else if !authSetTokenNoExtend && (appID == "" || appSecret == "") {
-		fmt.Fprintln(os.Stderr, "note: META_APP_ID / META_APP_SECRET not set — saving token as-is (not extended)")
-		fmt.Fprintln(os.Stderr, "      to extend later: meta-adlib auth extend-token <token> --save")
+		slog.Info("META_APP_ID / META_APP_SECRET not set — saving token as-is (not extended); to extend later: meta-adlib auth extend-token <token> --save")
 	}
 
 	fmt.Println("validating token...")
@@ -179,18 +382,20 @@ func runAuthSetToken(cmd *cobra.Command, args []string) error {
 		UserID:         userID,
 		UserName:       userName,
 		TokenExpiresAt: expiresAt,
+		Scopes:         lookupScopes(finalToken),
 	}
 
 	if err := config.Save(newCfg); err != nil {
 		return fmt.Errorf("failed to save config: %w", err)
 	}
 
-	fmt.Printf("token saved — authenticated as %s (ID: %s)\n", userName, userID)
+	fmt.Printf("token saved to profile %s — authenticated as %s (ID: %s)\n", activeProfile(), userName, userID)
 	if expiresAt != 0 {
 		fmt.Printf("  expires: %s (%d days)\n",
 			time.Unix(expiresAt, 0).Format("2006-01-02"),
 			newCfg.DaysUntilExpiry())
 	}
+	printScopes("  scopes:  ", newCfg.Scopes)
 	fmt.Printf("  config:  %s\n", config.Path())
 	return nil
 }
@@ -202,10 +407,10 @@ func runAuthExtendToken(cmd *cobra.Command, args []string) error {
 	appSecret := os.Getenv("META_APP_SECRET")
 
 	if appID == "" {
-		return fmt.Errorf("META_APP_ID not set — export META_APP_ID=<your_app_id>")
+		return usageErrorf("META_APP_ID not set — export META_APP_ID=<your_app_id>")
 	}
 	if appSecret == "" {
-		return fmt.Errorf("META_APP_SECRET not set — export META_APP_SECRET=<your_app_secret>")
+		return usageErrorf("META_APP_SECRET not set — export META_APP_SECRET=<your_app_secret>")
 	}
 
 	fmt.Println("exchanging for long-lived token...")
@@ -226,6 +431,7 @@ func runAuthExtendToken(cmd *cobra.Command, args []string) error {
 			UserID:         userID,
 			UserName:       userName,
 			TokenExpiresAt: expiresAt,
+			Scopes:         lookupScopes(longToken),
 		}
 		if err := config.Save(newCfg); err != nil {
 			return fmt.Errorf("failed to save config: %w", err)
@@ -236,6 +442,7 @@ func runAuthExtendToken(cmd *cobra.Command, args []string) error {
 				time.Unix(expiresAt, 0).Format("2006-01-02"),
 				newCfg.DaysUntilExpiry())
 		}
+		printScopes("  scopes:  ", newCfg.Scopes)
 		fmt.Printf("  config:  %s\n", config.Path())
 	} else {
 		fmt.Printf("\nlong-lived token:\n%s\n", longToken)
@@ -255,10 +462,10 @@ func runAuthRefresh(cmd *cobra.Command, args []string) error {
 	appSecret := os.Getenv("META_APP_SECRET")
 
 	if appID == "" {
-		return fmt.Errorf("META_APP_ID not set — export META_APP_ID=<your_app_id>")
+		return usageErrorf("META_APP_ID not set — export META_APP_ID=<your_app_id>")
 	}
 	if appSecret == "" {
-		return fmt.Errorf("META_APP_SECRET not set — export META_APP_SECRET=<your_app_secret>")
+		return usageErrorf("META_APP_SECRET not set — export META_APP_SECRET=<your_app_secret>")
 	}
 
 	c, err := config.Load()
@@ -266,7 +473,7 @@ func runAuthRefresh(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to load config: %w", err)
 	}
 	if c.AccessToken == "" {
-		return fmt.Errorf("not authenticated — run: meta-adlib auth set-token <token>")
+		return authErrorf("not authenticated — run: meta-adlib auth set-token <token>")
 	}
 
 	// Show current expiry before refreshing
@@ -289,6 +496,10 @@ func runAuthRefresh(cmd *cobra.Command, args []string) error {
 		UserID:         c.UserID,
 		UserName:       c.UserName,
 		TokenExpiresAt: expiresAt,
+		Scopes:         lookupScopes(newToken),
+	}
+	if newCfg.Scopes == nil {
+		newCfg.Scopes = c.Scopes
 	}
 	if err := config.Save(newCfg); err != nil {
 		return fmt.Errorf("failed to save refreshed token: %w", err)
@@ -329,13 +540,7 @@ func exchangeToLongLived(shortToken, appID, appSecret string) (string, int64, er
 // metaTokenFetch performs a GET to a Meta token endpoint and returns
 // (accessToken, expiresAtUnix, error).
 func metaTokenFetch(reqURL string) (string, int64, error) {
-	resp, err := http.Get(reqURL) //nolint:noctx
-	if err != nil {
-		return "", 0, err
-	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
+	body, err := authGet(reqURL)
 	if err != nil {
 		return "", 0, err
 	}
@@ -359,35 +564,177 @@ func metaTokenFetch(reqURL string) (string, int64, error) {
 	return result.AccessToken, expiresAt, nil
 }
 
+// orDash returns s, or "-" when s is empty.
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
 // fetchMe calls GET /me and returns (userID, userName, error).
 func fetchMe(token string) (string, string, error) {
 	params := url.Values{}
 	params.Set("access_token", token)
 	params.Set("fields", "id,name")
 
-	resp, err := http.Get(metaMeURL + "?" + params.Encode()) //nolint:noctx
-	if err != nil {
-		return "", "", err
-	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
+	body, err := authGet(metaMeURL + "?" + params.Encode())
 	if err != nil {
 		return "", "", err
 	}
 
 	var result struct {
-		ID    string `json:"id"`
-		Name  string `json:"name"`
-		Error *struct {
-			Message string `json:"message"`
-		} `json:"error"`
+		ID    string         `json:"id"`
+		Name  string         `json:"name"`
+		Error *api.MetaError `json:"error"`
 	}
 	if err := json.Unmarshal(body, &result); err != nil {
 		return "", "", fmt.Errorf("parsing /me response: %w", err)
 	}
 	if result.Error != nil {
-		return "", "", fmt.Errorf("meta api error: %s", result.Error.Message)
+		return "", "", result.Error
 	}
 	return result.ID, result.Name, nil
 }
+
+// lookupScopes returns the permissions granted to token via /debug_token. On
+// failure it warns and returns nil, since scopes are informational only.
+func lookupScopes(token string) []string {
+	params := url.Values{}
+	params.Set("input_token", token)
+	params.Set("access_token", token)
+
+	body, err := authGet(metaDebugURL + "?" + params.Encode())
+	if err == nil {
+		var result struct {
+			Data struct {
+				Scopes []string `json:"scopes"`
+			} `json:"data"`
+			Error *struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		switch {
+		case json.Unmarshal(body, &result) != nil:
+			err = fmt.Errorf("parsing /debug_token response")
+		case result.Error != nil:
+			err = fmt.Errorf("meta api error: %s", result.Error.Message)
+		default:
+			return result.Data.Scopes
+		}
+	}
+	slog.Warn(fmt.Sprintf("could not look up token scopes: %v", err))
+	return nil
+}
+
+// printScopes prints the granted scopes after label and warns about any
+// adLibraryScopes that are missing. Unknown (nil) scopes get a hint instead.
+func printScopes(label string, scopes []string) {
+	if scopes == nil {
+		fmt.Printf("%sunknown — re-run: meta-adlib auth set-token <token> to record them\n", label)
+		return
+	}
+	fmt.Printf("%s%s\n", label, output.JoinStrings(scopes, ", "))
+	if missing := missingScopes(scopes); len(missing) > 0 {
+		slog.Warn(fmt.Sprintf("token lacks scope(s) needed for the Ad Library: %s — regenerate it with these permissions",
+			strings.Join(missing, ", ")))
+	}
+}
+
+// missingScopes returns the adLibraryScopes not present in granted.
+func missingScopes(granted []string) []string {
+	var missing []string
+	for _, want := range adLibraryScopes {
+		if !slices.Contains(granted, want) {
+			missing = append(missing, want)
+		}
+	}
+	return missing
+}
+
+// clockSkew returns how far the local clock is ahead of Meta's servers (negative
+// when behind), taken from the Date header of a lightweight unauthenticated call.
+func clockSkew() (time.Duration, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, metaMeURL, nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("User-Agent", userAgent())
+	resp, err := authHTTPClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	resp.Body.Close()
+
+	serverTime, err := http.ParseTime(resp.Header.Get("Date"))
+	if err != nil {
+		return 0, fmt.Errorf("no usable Date header: %w", err)
+	}
+	return time.Since(serverTime), nil
+}
+
+// warnClockSkew prints a warning when the local clock is far enough off that
+// token expiry math (based on local time) can't be trusted. Network failures
+// are ignored: the check is best-effort.
+func warnClockSkew() {
+	skew, err := clockSkew()
+	if err != nil || skew.Abs() < clockSkewWarnAt {
+		return
+	}
+	direction := "ahead of"
+	if skew < 0 {
+		direction = "behind"
+	}
+	slog.Warn(fmt.Sprintf("local clock is %s %s Meta's servers — token expiry dates may be wrong; sync your system clock",
+		skew.Abs().Round(time.Second), direction))
+}
+
+// authGet performs a GET with a short timeout, retrying network errors and 5xx
+// responses with a doubling backoff. 4xx bodies are returned as-is so callers
+// can surface Meta's error message.
+func authGet(reqURL string) ([]byte, error) {
+	backoff := time.Second
+	var lastErr error
+	for attempt := 1; attempt <= authAttempts; attempt++ {
+		resp, err := authDo(reqURL)
+		if err == nil {
+			body, readErr := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			switch {
+			case readErr != nil:
+				err = readErr
+			case resp.StatusCode >= 500:
+				err = fmt.Errorf("HTTP %d", resp.StatusCode)
+			default:
+				return body, nil
+			}
+		}
+		lastErr = err
+
+		if attempt < authAttempts {
+			slog.Info("retrying auth request", "attempt", attempt+1, "in", backoff, "err", err)
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+	}
+	return nil, fmt.Errorf("after %d attempts: %w", authAttempts, lastErr)
+}
+
+// authDo sends a single GET with the CLI's User-Agent.
+func authDo(reqURL string) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodGet, reqURL, nil) //nolint:noctx
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", userAgent())
+	slog.Debug("GET", "url", logging.RedactURL(reqURL))
+	resp, err := authHTTPClient.Do(req)
+	// Transport errors quote the URL, token and app secret included.
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		urlErr.URL = logging.RedactURL(urlErr.URL)
+	}
+	return resp, err
+}
@@ -0,0 +1,195 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/spf13/cobra"
+	"github.com/the20100/meta-ad-library-cli/internal/api"
+	"github.com/the20100/meta-ad-library-cli/internal/output"
+)
+
+var (
+	browseLimit  int
+	browseFields string
+)
+
+var browseCmd = &cobra.Command{
+	Use:   "browse",
+	Short: "Browse search results in an interactive terminal UI",
+	Long: `Runs a search (same filters as the search command) and opens a scrollable
+list of the results. Press Enter on an ad to see its full details, including
+the snapshot link.
+
+Keys:
+  ↑/↓, k/j      Move (list) or scroll (details)
+  PgUp/PgDn     Move or scroll by a page
+  Enter         Open details
+  Esc, ←        Back to the list
+  q, Ctrl-C     Quit
+
+Examples:
+  meta-adlib browse --query "climate" --country FR
+  meta-adlib browse --page-id 123456789 --country DE --limit 200`,
+	RunE: runBrowse,
+}
+
+func init() {
+	addSearchFlags(browseCmd.Flags())
+	browseCmd.Flags().IntVar(&browseLimit, "limit", 100, "Maximum number of results (0 = fetch all pages)")
+	browseCmd.Flags().StringVar(&browseFields, "fields", api.FieldsDetail, "Comma-separated list of fields to return")
+	addWithTokenFlag(browseCmd.Flags())
+
+	rootCmd.AddCommand(browseCmd)
+}
+
+func runBrowse(cmd *cobra.Command, args []string) error {
+	params, err := searchOpts.params(browseFields)
+	if err != nil {
+		return err
+	}
+
+	res, err := searchOpts.searchAds(params, browseLimit)
+	if err != nil {
+		return err
+	}
+	items := res.items
+	if res.truncated {
+		warnTruncated(browseLimit, false)
+	}
+	if len(items) == 0 {
+		fmt.Println("no ads found")
+		return nil
+	}
+
+	ads := parseAds(items)
+
+	// The TUI does its own layout; OSC 8 escapes would confuse its width math.
+	output.NoColor = true
+
+	_, err = tea.NewProgram(&browseModel{ads: ads}, tea.WithAltScreen()).Run()
+	return err
+}
+
+// browseModel is the bubbletea model for the browse UI: a list of ads and an
+// optional detail pane for the selected one.
+type browseModel struct {
+	ads    []api.AdArchiveRecord
+	cursor int // selected ad
+	offset int // first ad visible in the list
+
+	detail      bool
+	detailLines []string
+	scroll      int
+
+	width, height int
+}
+
+func (m *browseModel) Init() tea.Cmd { return nil }
+
+func (m *browseModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "q", "ctrl+c":
+			return m, tea.Quit
+		case "enter":
+			if !m.detail {
+				var buf bytes.Buffer
+				writeAdDetail(&buf, m.ads[m.cursor])
+				m.detailLines = strings.Split(strings.TrimRight(buf.String(), "\n"), "\n")
+				m.detail, m.scroll = true, 0
+			}
+		case "esc", "left", "h":
+			m.detail = false
+		case "up", "k":
+			m.move(-1)
+		case "down", "j":
+			m.move(1)
+		case "pgup":
+			m.move(-m.pageSize())
+		case "pgdown":
+			m.move(m.pageSize())
+		}
+	}
+	return m, nil
+}
+
+// move shifts the list cursor or the detail scroll position by delta.
+func (m *browseModel) move(delta int) {
+	if m.detail {
+		m.scroll = clamp(m.scroll+delta, 0, max(len(m.detailLines)-m.pageSize(), 0))
+		return
+	}
+	m.cursor = clamp(m.cursor+delta, 0, len(m.ads)-1)
+	if m.cursor < m.offset {
+		m.offset = m.cursor
+	}
+	if m.cursor >= m.offset+m.pageSize() {
+		m.offset = m.cursor - m.pageSize() + 1
+	}
+}
+
+// pageSize is the number of content lines between the header and footer.
+func (m *browseModel) pageSize() int {
+	return max(m.height-3, 1)
+}
+
+func (m *browseModel) View() string {
+	var b strings.Builder
+	if m.detail {
+		a := m.ads[m.cursor]
+		fmt.Fprintf(&b, "Ad %s — %s\n\n", a.ID, a.PageName)
+		end := min(m.scroll+m.pageSize(), len(m.detailLines))
+		for _, line := range m.detailLines[m.scroll:end] {
+			b.WriteString(m.fit(line) + "\n")
+		}
+		b.WriteString("\n↑/↓ scroll · esc back · q quit")
+		return b.String()
+	}
+
+	fmt.Fprintf(&b, "  %-17s %-25s %-16s %-8s %s\n", "ID", "PAGE", "STARTED", "STATUS", "BODY")
+	end := min(m.offset+m.pageSize(), len(m.ads))
+	for i := m.offset; i < end; i++ {
+		marker := "  "
+		if i == m.cursor {
+			marker = "> "
+		}
+		b.WriteString(m.fit(marker+browseRow(m.ads[i])) + "\n")
+	}
+	fmt.Fprintf(&b, "\n%d/%d · ↑/↓ move · enter details · q quit", m.cursor+1, len(m.ads))
+	return b.String()
+}
+
+// fit truncates line to the terminal width, if known.
+func (m *browseModel) fit(line string) string {
+	if m.width <= 0 {
+		return line
+	}
+	return output.Truncate(line, m.width)
+}
+
+// browseRow renders one ad as a fixed-width list line.
+func browseRow(a api.AdArchiveRecord) string {
+	status := "inactive"
+	if a.AdDeliveryStopTime == "" {
+		status = "active"
+	}
+	body := "-"
+	if len(a.AdCreativeBodies) > 0 {
+		body = a.AdCreativeBodies[0]
+	} else if len(a.AdCreativeLinkTitles) > 0 {
+		body = a.AdCreativeLinkTitles[0]
+	}
+	body = strings.Join(strings.Fields(body), " ")
+	return fmt.Sprintf("%-17s %-25s %-16s %-8s %s",
+		a.ID, output.Truncate(a.PageName, 25), output.FormatTime(a.AdDeliveryStartTime), status, body)
+}
+
+func clamp(v, lo, hi int) int {
+	return max(lo, min(v, hi))
+}
@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/the20100/meta-ad-library-cli/internal/cache"
+	"github.com/the20100/meta-ad-library-cli/internal/output"
+)
+
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Inspect and clear the local cache",
+	Long: `The cache directory holds data kept between runs, such as the results
+saved with --save-last and exchange rates fetched for --fx-source. Everything
+in it is safe to delete.
+
+The directory is $XDG_CACHE_HOME/meta-ad-library when XDG_CACHE_HOME is set,
+else meta-ad-library in the OS user cache directory.`,
+}
+
+var cacheInfoCmd = &cobra.Command{
+	Use:   "info",
+	Short: "Show the cache location, entry count and size",
+	Long: `Shows where the cache lives, how many files it holds and their total size,
+then lists each file with its size and modification time.
+
+Examples:
+  meta-adlib cache info
+  meta-adlib cache info --json | jq .size`,
+	Args: cobra.NoArgs,
+	RunE: runCacheInfo,
+}
+
+var cacheClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Delete everything in the cache",
+	Long: `Deletes the cache directory and everything in it. The next run that caches
+something creates it again.
+
+Examples:
+  meta-adlib cache clear`,
+	Args: cobra.NoArgs,
+	RunE: runCacheClear,
+}
+
+func init() {
+	cacheCmd.AddCommand(cacheInfoCmd, cacheClearCmd)
+	rootCmd.AddCommand(cacheCmd)
+}
+
+func runCacheInfo(cmd *cobra.Command, args []string) error {
+	dir, err := cache.Dir()
+	if err != nil {
+		return err
+	}
+	entries, err := cache.Entries()
+	if err != nil {
+		return err
+	}
+	var size int64
+	for _, e := range entries {
+		size += e.Size
+	}
+
+	if output.IsJSON(cmd) {
+		if entries == nil {
+			entries = []cache.Entry{}
+		}
+		return output.PrintJSON(map[string]any{
+			"location": dir,
+			"entries":  len(entries),
+			"size":     size,
+			"files":    entries,
+		}, output.IsPretty(cmd))
+	}
+
+	output.PrintKeyValue([][]string{
+		{"Location", dir},
+		{"Entries", fmt.Sprint(len(entries))},
+		{"Size", formatBytes(size)},
+	})
+	if len(entries) == 0 {
+		return nil
+	}
+	fmt.Println()
+	rows := make([][]string, len(entries))
+	for i, e := range entries {
+		rows[i] = []string{e.Name, formatBytes(e.Size), e.ModTime.Local().Format("2006-01-02 15:04")}
+	}
+	output.PrintTable([]string{"FILE", "SIZE", "MODIFIED"}, rows)
+	return nil
+}
+
+func runCacheClear(cmd *cobra.Command, args []string) error {
+	dir, err := cache.Dir()
+	if err != nil {
+		return err
+	}
+	n, err := cache.Clear()
+	if err != nil {
+		return err
+	}
+	if output.IsJSON(cmd) {
+		return output.PrintJSON(map[string]any{"location": dir, "removed": n}, output.IsPretty(cmd))
+	}
+	fmt.Printf("removed %d cached file(s) from %s\n", n, dir)
+	return nil
+}
+
+// formatBytes renders a byte count with a binary unit, e.g. "1.5 KiB".
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,251 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/the20100/meta-ad-library-cli/internal/api"
+)
+
+var exportCheckpoint string
+
+// throttleWait is how long a checkpointed export pauses before the next page
+// once Meta reports app usage above --rate-warn-at. Usage is measured over a
+// rolling hour, so waiting lets it come down.
+const throttleWait = time.Minute
+
+// exportProgress is the --checkpoint file: enough to resume an ndjson export
+// after the last page fully written to --out.
+type exportProgress struct {
+	// Params are the search parameters, so a checkpoint isn't resumed by a
+	// different search.
+	Params string `json:"params"`
+	Out    string `json:"out"`
+	// After is the cursor of the next page; empty once Done.
+	After string `json:"after,omitempty"`
+	// Count and Pages are the ads and pages written so far; Bytes is the
+	// size of --out after them. Anything past Bytes is from a page whose
+	// checkpoint wasn't saved, and is cut off on resume.
+	Count     int       `json:"count"`
+	Pages     int       `json:"pages"`
+	Bytes     int64     `json:"bytes"`
+	Done      bool      `json:"done"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// loadProgress reads a checkpoint, returning nil when the file doesn't exist.
+func loadProgress(path string) (*exportProgress, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var p exportProgress
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("parsing checkpoint %s: %w", path, err)
+	}
+	return &p, nil
+}
+
+// save replaces the checkpoint file, writing then renaming so an interrupted
+// save never leaves a truncated file.
+func (p *exportProgress) save(path string) error {
+	p.UpdatedAt = time.Now().UTC()
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false) // keep the & in Params readable
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(p); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, buf.Bytes(), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// runCheckpointedExport pages through the search described by the shared
+// flags, appending each page to --out as NDJSON and recording progress in the
+// --checkpoint file after every page. Run again, it resumes after the last
+// recorded page instead of starting over.
+func runCheckpointedExport() error {
+	if exportOut == "" || exportOut == "-" {
+		return usageErrorf("--checkpoint needs an --out file to resume into")
+	}
+	if exportGzip || strings.HasSuffix(exportOut, ".gz") {
+		return usageErrorf("--checkpoint can't resume into a compressed file; export uncompressed and gzip afterwards")
+	}
+	params, err := searchOpts.params(exportFields)
+	if err != nil {
+		return err
+	}
+	typed := searchOpts.paramsByType(params)
+	if len(typed) > 1 {
+		return usageErrorf("--checkpoint supports a single --type")
+	}
+	params = typed[0].params
+	key := params.Encode()
+
+	prog, err := loadProgress(exportCheckpoint)
+	if err != nil {
+		return err
+	}
+	switch {
+	case prog == nil:
+		prog = &exportProgress{Params: key, Out: exportOut}
+	case prog.Params != key || prog.Out != exportOut:
+		return usageErrorf("checkpoint %s belongs to a different export (out: %s); delete it or pick another --checkpoint file", exportCheckpoint, prog.Out)
+	case prog.Done:
+		slog.Info(fmt.Sprintf("checkpoint says the export is complete (%d ad(s) in %s); delete %s to export again", prog.Count, exportOut, exportCheckpoint))
+		return nil
+	default:
+		slog.Info(fmt.Sprintf("resuming after %d ad(s) in %d page(s)", prog.Count, prog.Pages))
+	}
+
+	f, err := openResumable(exportOut, prog.Bytes)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	opts := api.SearchOptions{Limit: exportLimit, After: prog.After}
+	if exportLimit > 0 {
+		opts.Limit = exportLimit - prog.Count
+		if opts.Limit <= 0 {
+			prog.Done, prog.After = true, ""
+			return prog.save(exportCheckpoint)
+		}
+	}
+	if prog.Count == 0 {
+		noteLimitCost(typed, opts)
+	}
+	// skip is how many ads to drop before writing, after a restart from the
+	// first page.
+	skip := 0
+	opts.OnPage = func(items []json.RawMessage, after string) error {
+		items = searchOpts.keep(items)
+		if skip > 0 {
+			n := min(skip, len(items))
+			items, skip = items[n:], skip-n
+			if skip > 0 {
+				// Keep the checkpoint as is until every ad already
+				// written has been passed, so an interrupted restart
+				// restarts again.
+				return nil
+			}
+		}
+		w := bufio.NewWriter(f)
+		if err := writeNDJSON(w, items); err != nil {
+			return err
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+		if err := f.Sync(); err != nil {
+			return err
+		}
+		info, err := f.Stat()
+		if err != nil {
+			return err
+		}
+		prog.Count += len(items)
+		prog.Pages++
+		prog.Bytes = info.Size()
+		prog.After = after
+		if err := prog.save(exportCheckpoint); err != nil {
+			return fmt.Errorf("saving checkpoint: %w", err)
+		}
+		slog.Debug(fmt.Sprintf("checkpoint: %d ad(s), %d page(s)", prog.Count, prog.Pages))
+		if after != "" {
+			throttleExport()
+		}
+		return nil
+	}
+
+	res, err := client.Search(params, opts)
+	var expired *api.CursorExpiredError
+	if opts.After != "" && errors.As(err, &expired) {
+		// The saved cursor is too old to resume from. Page through the
+		// search again, passing over the ads --out already holds; this
+		// assumes Meta returns them in the same order as before.
+		slog.Warn(fmt.Sprintf("the checkpoint's paging cursor has expired; restarting the search and skipping the %d ad(s) already written", prog.Count))
+		skip = prog.Count
+		opts.After = ""
+		if exportLimit > 0 {
+			opts.Limit = exportLimit
+		}
+		res, err = client.Search(params, opts)
+	}
+	if err != nil {
+		return fmt.Errorf("%w — progress is saved in %s; re-run the same command to resume", err, exportCheckpoint)
+	}
+	if res.DeadlineReached {
+		slog.Warn(fmt.Sprintf("stopped at the deadline after %d ad(s); re-run the same command to resume", prog.Count))
+		return nil
+	}
+	prog.Done, prog.After = true, ""
+	if err := prog.save(exportCheckpoint); err != nil {
+		return fmt.Errorf("saving checkpoint: %w", err)
+	}
+	if res.Truncated {
+		warnTruncated(exportLimit, false)
+	}
+	fmt.Printf("%d ad(s) written to %s\n", prog.Count, exportOut)
+	return nil
+}
+
+// throttleExport pauses before the next page while Meta reports app usage
+// above --rate-warn-at, so a long export slows down instead of running into
+// the rate limit. The wait ends early once the client's context is done
+// (--deadline), and the next request then stops the export.
+func throttleExport() {
+	if u := client.Usage(); u.HasAppUsage && u.AppUsage > client.RateWarnAt() {
+		slog.Info(fmt.Sprintf("app usage at %d%%; waiting %s before the next page", u.AppUsage, throttleWait))
+		select {
+		case <-client.Context().Done():
+		case <-time.After(throttleWait):
+		}
+	}
+}
+
+// openResumable opens path for appending after its first size bytes,
+// creating it when size is 0 and cutting off anything written after the
+// last checkpoint.
+func openResumable(path string, size int64) (*os.File, error) {
+	flags := os.O_WRONLY | os.O_CREATE
+	if size == 0 {
+		flags |= os.O_TRUNC
+	}
+	f, err := os.OpenFile(path, flags, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	if size > 0 {
+		info, err := f.Stat()
+		if err == nil && info.Size() < size {
+			err = fmt.Errorf("%s is shorter than the checkpoint records (%d < %d bytes); delete the checkpoint to start over", path, info.Size(), size)
+		}
+		if err == nil {
+			err = f.Truncate(size)
+		}
+		if err == nil {
+			_, err = f.Seek(size, io.SeekStart)
+		}
+		if err != nil {
+			f.Close()
+			return nil, err
+		}
+	}
+	return f, nil
+}
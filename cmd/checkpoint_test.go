@@ -0,0 +1,161 @@
+package cmd
+
+import (
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/the20100/meta-ad-library-cli/internal/api"
+)
+
+func TestOpenResumable(t *testing.T) {
+	tests := []struct {
+		name    string
+		existed string
+		size    int64
+		write   string
+		want    string
+		wantErr bool
+	}{
+		{name: "new file", size: 0, write: "a\n", want: "a\n"},
+		{name: "size 0 truncates", existed: "old\n", size: 0, write: "a\n", want: "a\n"},
+		{name: "appends after size", existed: "a\n", size: 2, write: "b\n", want: "a\nb\n"},
+		{name: "cuts off unrecorded bytes", existed: "a\npartial", size: 2, write: "b\n", want: "a\nb\n"},
+		{name: "shorter than recorded", existed: "a\n", size: 10, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "out.ndjson")
+			if tt.existed != "" {
+				if err := os.WriteFile(path, []byte(tt.existed), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			f, err := openResumable(path, tt.size)
+			if tt.wantErr {
+				if err == nil {
+					f.Close()
+					t.Fatal("openResumable succeeded; want error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := f.WriteString(tt.write); err != nil {
+				t.Fatal(err)
+			}
+			if err := f.Close(); err != nil {
+				t.Fatal(err)
+			}
+			got, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("file = %q; want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProgressSaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "export.ckpt")
+	p, err := loadProgress(path)
+	if err != nil || p != nil {
+		t.Fatalf("loadProgress(missing) = %v, %v; want nil, nil", p, err)
+	}
+
+	want := &exportProgress{Params: "q=a&b", Out: "ads.ndjson", After: "cur", Count: 250, Pages: 3, Bytes: 4096}
+	if err := want.save(path); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path + ".tmp"); !os.IsNotExist(err) {
+		t.Errorf("temporary file left behind: %v", err)
+	}
+	got, err := loadProgress(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.UpdatedAt.Equal(want.UpdatedAt) {
+		t.Errorf("UpdatedAt = %v; want %v", got.UpdatedAt, want.UpdatedAt)
+	}
+	got.UpdatedAt = want.UpdatedAt
+	if *got != *want {
+		t.Errorf("loadProgress() = %+v; want %+v", got, want)
+	}
+
+	if err := os.WriteFile(path, []byte("{"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadProgress(path); err == nil {
+		t.Error("loadProgress of a malformed file succeeded; want error")
+	}
+}
+
+// roundTripFunc serves requests from a function instead of the network.
+type roundTripFunc func(*http.Request) *http.Response
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) { return f(r), nil }
+
+func TestCheckpointRestartsExpiredCursor(t *testing.T) {
+	dir := t.TempDir()
+	out := filepath.Join(dir, "ads.ndjson")
+	ckpt := filepath.Join(dir, "ads.ckpt")
+
+	prevOpts, prevOut, prevCkpt, prevFields, prevLimit, prevClient := searchOpts, exportOut, exportCheckpoint, exportFields, exportLimit, client
+	t.Cleanup(func() {
+		searchOpts, exportOut, exportCheckpoint, exportFields, exportLimit, client = prevOpts, prevOut, prevCkpt, prevFields, prevLimit, prevClient
+	})
+	searchOpts = searchFilters{Query: "shoes", Countries: []string{"FR"}, Status: "ALL"}
+	exportOut, exportCheckpoint, exportFields, exportLimit = out, ckpt, "id", 0
+
+	params, err := searchOpts.params(exportFields)
+	if err != nil {
+		t.Fatal(err)
+	}
+	written := "{\"id\":\"1\"}\n{\"id\":\"2\"}\n"
+	if err := os.WriteFile(out, []byte(written), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	prog := &exportProgress{Params: searchOpts.paramsByType(params)[0].params.Encode(), Out: out, After: "stale", Count: 2, Pages: 1, Bytes: int64(len(written))}
+	if err := prog.save(ckpt); err != nil {
+		t.Fatal(err)
+	}
+
+	prevTransport := http.DefaultTransport
+	t.Cleanup(func() { http.DefaultTransport = prevTransport })
+	http.DefaultTransport = roundTripFunc(func(r *http.Request) *http.Response {
+		status, body := http.StatusOK, ""
+		switch r.URL.Query().Get("after") {
+		case "stale":
+			status, body = http.StatusBadRequest, `{"error":{"message":"Invalid cursor","code":100}}`
+		case "":
+			body = `{"data":[{"id":"1"},{"id":"2"},{"id":"3"}],"paging":{"cursors":{"after":"p2"},"next":"https://graph.facebook.com/ads_archive?after=p2"}}`
+		case "p2":
+			body = `{"data":[{"id":"4"}]}`
+		}
+		return &http.Response{StatusCode: status, Header: http.Header{}, Body: io.NopCloser(strings.NewReader(body)), Request: r}
+	})
+	client = api.NewClient("tok")
+
+	if err := runCheckpointedExport(); err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := written + "{\"id\":\"3\"}\n{\"id\":\"4\"}\n"; string(got) != want {
+		t.Errorf("--out = %q; want %q", got, want)
+	}
+	if prog, err = loadProgress(ckpt); err != nil {
+		t.Fatal(err)
+	}
+	if !prog.Done || prog.Count != 4 {
+		t.Errorf("checkpoint = %+v; want done with 4 ads", prog)
+	}
+}
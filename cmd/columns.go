@@ -0,0 +1,267 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/the20100/meta-ad-library-cli/internal/api"
+	"github.com/the20100/meta-ad-library-cli/internal/output"
+)
+
+// adColumn is a column of the ads table. fields are the API fields it is
+// computed from; the column is blank when none of them was requested (all
+// of them, for derived columns).
+type adColumn struct {
+	header string
+	fields []string
+	value  func(a api.AdArchiveRecord) string
+}
+
+// adColumns are the columns --columns can pick from.
+var adColumns = map[string]adColumn{
+	"id":          {"ID", []string{"id"}, func(a api.AdArchiveRecord) string { return output.Hyperlink(a.AdSnapshotURL, a.ID) }},
+	"page":        {"PAGE", []string{"page_name"}, func(a api.AdArchiveRecord) string { return output.Truncate(a.PageName, 25) }},
+	"page_id":     {"PAGE ID", []string{"page_id"}, func(a api.AdArchiveRecord) string { return orDash(a.PageID) }},
+	"started":     {"STARTED", []string{"ad_delivery_start_time"}, func(a api.AdArchiveRecord) string { return output.FormatTime(a.AdDeliveryStartTime) }},
+	"stopped":     {"STOPPED", []string{"ad_delivery_stop_time"}, func(a api.AdArchiveRecord) string { return output.FormatTime(a.AdDeliveryStopTime) }},
+	"status":      {"STATUS", []string{"ad_delivery_stop_time"}, adStatus},
+	"spend":       {"SPEND", []string{"spend"}, spendCell},
+	"impressions": {"IMPRESSIONS", []string{"impressions"}, func(a api.AdArchiveRecord) string { return rangeCell(a.Impressions) }},
+	"spend_per_impression": {"SPEND/IMPR", []string{"spend", "impressions"}, func(a api.AdArchiveRecord) string {
+		v, ok := spendPerImpression(a)
+		if !ok {
+			return "-"
+		}
+		return withCurrency(fmt.Sprintf("%.4f", v), a)
+	}},
+	"platforms": {"PLATFORMS", []string{"publisher_platforms"}, func(a api.AdArchiveRecord) string {
+		return output.Truncate(output.JoinStrings(a.PublisherPlatforms, ", "), 20)
+	}},
+	"languages":  {"LANGUAGES", []string{"languages"}, func(a api.AdArchiveRecord) string { return output.JoinStrings(a.Languages, ", ") }},
+	"body":       {"BODY", []string{"ad_creative_bodies", "ad_creative_link_titles"}, adBody},
+	"permalink":  {"PERMALINK", []string{"id"}, func(a api.AdArchiveRecord) string { return orDash(a.Permalink()) }},
+	"spend_tier": {"TIER", []string{"spend"}, func(a api.AdArchiveRecord) string { return orDash(spendTierOf(a)) }},
+	// query's cells are filled in by printAdsTable, from adQueries.
+	"query": {"QUERY", nil, nil},
+}
+
+// defaultAdColumns is the ads table layout when --columns is not given.
+var defaultAdColumns = []string{"id", "page", "started", "status", "spend", "platforms", "body"}
+
+// columnsFlag holds --columns; nil means defaultAdColumns.
+var columnsFlag []string
+
+// checkColumns validates --columns before any API call.
+func checkColumns() error {
+	for _, c := range columnsFlag {
+		if _, ok := adColumns[c]; !ok {
+			names := make([]string, 0, len(adColumns))
+			for n := range adColumns {
+				names = append(names, n)
+			}
+			sort.Strings(names)
+			return usageErrorf("unknown column %q — available: %s", c, strings.Join(names, ", "))
+		}
+	}
+	return nil
+}
+
+// tableColumns returns the columns to print. The default layout gains a
+// QUERY column after ID with --queries-file, and a TIER column after SPEND
+// when spend tiers are in use.
+func tableColumns() []string {
+	if len(columnsFlag) > 0 {
+		return columnsFlag
+	}
+	cols := defaultAdColumns
+	if searchQueriesFile != "" {
+		cols = slices.Insert(slices.Clone(cols), slices.Index(cols, "id")+1, "query")
+	}
+	if spendTiers != nil {
+		cols = slices.Insert(slices.Clone(cols), slices.Index(cols, "spend")+1, "spend_tier")
+	}
+	return cols
+}
+
+// noteUnrequestedColumns explains, once, which table columns will be empty
+// because fields (the comma-separated fields sent to the API) leaves out
+// what they show.
+func noteUnrequestedColumns(fields string) {
+	requested := strings.Split(fields, ",")
+	var blank, missing []string
+	for _, name := range tableColumns() {
+		col := adColumns[name]
+		var lacking []string
+		for _, f := range col.fields {
+			if !slices.Contains(requested, f) {
+				lacking = append(lacking, f)
+			}
+		}
+		// A derived column needs every field; others need any one of them.
+		derived := name == "spend_per_impression"
+		if len(lacking) == 0 || (!derived && len(lacking) < len(col.fields)) {
+			continue
+		}
+		blank = append(blank, name)
+		for _, f := range lacking {
+			if !slices.Contains(missing, f) {
+				missing = append(missing, f)
+			}
+		}
+	}
+	if len(blank) > 0 {
+		slog.Info(fmt.Sprintf("column(s) %s can't be filled in because --fields doesn't request %s",
+			strings.Join(blank, ", "), strings.Join(missing, ", ")))
+	}
+}
+
+func adStatus(a api.AdArchiveRecord) string {
+	if a.AdDeliveryStopTime == "" {
+		return "active"
+	}
+	return "inactive"
+}
+
+func adBody(a api.AdArchiveRecord) string {
+	switch {
+	case len(a.AdCreativeBodies) > 0:
+		return output.Truncate(a.AdCreativeBodies[0], 50)
+	case len(a.AdCreativeLinkTitles) > 0:
+		return output.Truncate(a.AdCreativeLinkTitles[0], 50)
+	}
+	return "-"
+}
+
+// rangeCell formats a spend or impressions range like RangeValue.String, with
+// the bounds grouped per --locale.
+func rangeCell(r *api.RangeValue) string {
+	if r == nil {
+		return "-"
+	}
+	if r.LowerBound == r.UpperBound {
+		return output.FormatNumber(r.LowerBound)
+	}
+	return output.FormatNumber(r.LowerBound) + "–" + output.FormatNumber(r.UpperBound)
+}
+
+// withCurrency appends the ad's currency to an amount, leaving "-" alone.
+func withCurrency(amount string, a api.AdArchiveRecord) string {
+	if amount == "-" || a.Currency == "" {
+		return amount
+	}
+	return amount + " " + a.Currency
+}
+
+// spendPerImpression estimates cost per impression from the midpoints of the
+// spend and impressions ranges. It reports false when either is missing or
+// impressions are zero.
+func spendPerImpression(a api.AdArchiveRecord) (float64, bool) {
+	if a.Spend == nil || a.Impressions == nil {
+		return 0, false
+	}
+	if _, ok := a.Spend.Lower(); !ok {
+		return 0, false
+	}
+	impressions := a.Impressions.Midpoint()
+	if impressions <= 0 {
+		return 0, false
+	}
+	return a.Spend.Midpoint() / impressions, true
+}
+
+// adsJSON prepares items for JSON output: derived keys are added, and with
+// --canonical every ad is re-encoded in a fixed key order.
+func adsJSON(items []json.RawMessage) ([]json.RawMessage, error) {
+	items, err := withDerivedFields(items)
+	if err != nil {
+		return nil, err
+	}
+	return canonicalAds(items)
+}
+
+// canonicalAds re-encodes each item in a fixed key order with --canonical,
+// and returns items unchanged otherwise.
+func canonicalAds(items []json.RawMessage) ([]json.RawMessage, error) {
+	if !canonicalFlag {
+		return items, nil
+	}
+	var err error
+	out := make([]json.RawMessage, len(items))
+	for i, item := range items {
+		if out[i], err = api.CanonicalJSON(item); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+// withDerivedFields adds computed keys to each JSON item: the ones named in
+// --columns (spend_per_impression, permalink), spend_converted with
+// --convert-to, spend_tier when spend tiers are in use, and source_query with
+// --queries-file. A key is null when it can't be computed. Items are
+// returned unchanged when nothing derived was asked for.
+func withDerivedFields(items []json.RawMessage) ([]json.RawMessage, error) {
+	perImpression := slices.Contains(columnsFlag, "spend_per_impression")
+	permalink := slices.Contains(columnsFlag, "permalink")
+	tier := spendTiers != nil
+	if !perImpression && !permalink && convertToFlag == "" && !tier && adQueries == nil {
+		return items, nil
+	}
+	queryOf := adQueries.tagger()
+	out := make([]json.RawMessage, len(items))
+	for i, item := range items {
+		var a api.AdArchiveRecord
+		var m map[string]json.RawMessage
+		if err := json.Unmarshal(item, &a); err != nil {
+			return nil, fmt.Errorf("parsing ad: %w", err)
+		}
+		if err := json.Unmarshal(item, &m); err != nil {
+			return nil, fmt.Errorf("parsing ad: %w", err)
+		}
+		if perImpression {
+			var v any
+			if spi, ok := spendPerImpression(a); ok {
+				v = spi
+			}
+			m["spend_per_impression"], _ = json.Marshal(v)
+		}
+		if permalink {
+			var v any
+			if p := a.Permalink(); p != "" {
+				v = p
+			}
+			m["permalink"], _ = json.Marshal(v)
+		}
+		if tier {
+			var v any
+			if t := spendTierOf(a); t != "" {
+				v = t
+			}
+			m["spend_tier"], _ = json.Marshal(v)
+		}
+		if adQueries != nil {
+			var v any
+			if q := queryOf(a.ID); q != "" {
+				v = q
+			}
+			m["source_query"], _ = json.Marshal(v)
+		}
+		if convertToFlag != "" {
+			var v any
+			if r, ok := convertedSpend(a); ok {
+				v = convertedRange{r.LowerBound, r.UpperBound, convertToFlag, true}
+			}
+			m["spend_converted"], _ = json.Marshal(v)
+		}
+		b, err := json.Marshal(m)
+		if err != nil {
+			return nil, err
+		}
+		out[i] = b
+	}
+	return out, nil
+}
@@ -0,0 +1,171 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/the20100/meta-ad-library-cli/internal/config"
+	"github.com/the20100/meta-ad-library-cli/internal/output"
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect and check the local config file",
+}
+
+var configShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Print the config file with tokens redacted",
+	Long: `Prints the local config file as JSON. Access tokens are masked, so the
+output is safe to paste into bug reports.
+
+Examples:
+  meta-adlib config show
+  meta-adlib config show --json | jq '.profiles | keys'`,
+	Args: cobra.NoArgs,
+	RunE: runConfigShow,
+}
+
+var configValidateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Check that the config file parses and holds a usable token",
+	Long: `Checks the local config file: it must exist and parse, and the active
+profile must have a non-empty token. A token whose known expiry has passed
+produces a warning.
+
+Exits non-zero when the config is broken, so it can gate scripts:
+  meta-adlib config validate && meta-adlib search ...`,
+	Args: cobra.NoArgs,
+	RunE: runConfigValidate,
+}
+
+var configSetCmd = &cobra.Command{
+	Use:   "set <key> <value>",
+	Short: "Change a setting shared by all profiles",
+	Long: `Stores a setting in the config file. An empty value clears it.
+
+Keys:
+  default-country   Country used by search-based commands and page ads when
+                    --country is omitted (ISO 3166, e.g. US)
+  default-limit     Result limit used by search and page ads when --limit is
+                    omitted (0 = fetch all pages)
+
+Examples:
+  meta-adlib config set default-country US
+  meta-adlib config set default-country ""
+  meta-adlib config set default-limit 100`,
+	Args: cobra.ExactArgs(2),
+	RunE: runConfigSet,
+}
+
+// configSettings maps each config set key to a function storing its value.
+var configSettings = map[string]func(f *config.File, value string) error{
+	"default-country": func(f *config.File, value string) error {
+		value = strings.ToUpper(value)
+		if value != "" && !isCountryCode(value) {
+			return usageErrorf("invalid default-country %q — expected a 2-letter ISO 3166 code", value)
+		}
+		f.DefaultCountry = value
+		return nil
+	},
+	"default-limit": func(f *config.File, value string) error {
+		if value == "" {
+			f.DefaultLimit = nil
+			return nil
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return usageErrorf("invalid default-limit %q — expected a non-negative integer (0 = all)", value)
+		}
+		f.DefaultLimit = &n
+		return nil
+	},
+}
+
+func init() {
+	configCmd.AddCommand(configShowCmd, configValidateCmd, configSetCmd)
+	rootCmd.AddCommand(configCmd)
+}
+
+func runConfigShow(cmd *cobra.Command, args []string) error {
+	f, err := config.LoadFile()
+	if err != nil {
+		return fmt.Errorf("failed to load config %s: %w", config.Path(), err)
+	}
+
+	redacted := *f
+	redacted.ActiveProfile = f.Active()
+	redacted.Profiles = make(map[string]*config.Config, len(f.Profiles))
+	for name, c := range f.Profiles {
+		masked := *c
+		masked.AccessToken = maskOrEmpty(c.AccessToken)
+		redacted.Profiles[name] = &masked
+	}
+
+	return output.PrintJSON(redacted, output.IsPretty(cmd) || !output.IsJSON(cmd))
+}
+
+func runConfigValidate(cmd *cobra.Command, args []string) error {
+	path := config.Path()
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("no config file at %s — run: meta-adlib auth set-token <token>", path)
+	}
+
+	f, err := config.LoadFile()
+	if err != nil {
+		return fmt.Errorf("config %s does not parse: %w", path, err)
+	}
+
+	name := f.Active()
+	c, ok := f.Profiles[name]
+	if !ok {
+		return fmt.Errorf("active profile %q not found in %s", name, path)
+	}
+	if c.AccessToken == "" {
+		return fmt.Errorf("profile %q has an empty access token — run: meta-adlib auth set-token <token>", name)
+	}
+	if c.IsExpired() {
+		slog.Warn(fmt.Sprintf("token in profile %q expired on %s — run: meta-adlib auth refresh",
+			name, c.ExpiresAt().Format("2006-01-02")))
+	}
+
+	fmt.Printf("config OK: %s (profile %s, user %s)\n", path, name, orDash(c.UserName))
+	return nil
+}
+
+func runConfigSet(cmd *cobra.Command, args []string) error {
+	key, value := args[0], args[1]
+	set, ok := configSettings[key]
+	if !ok {
+		keys := make([]string, 0, len(configSettings))
+		for k := range configSettings {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		return usageErrorf("unknown setting %q — available: %s", key, strings.Join(keys, ", "))
+	}
+
+	f, err := config.LoadFile()
+	if err != nil {
+		return fmt.Errorf("failed to load config %s: %w", config.Path(), err)
+	}
+	if err := set(f, value); err != nil {
+		return err
+	}
+	if err := config.SaveFile(f); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+
+	if value == "" {
+		fmt.Printf("%s cleared\n", key)
+	} else {
+		fmt.Printf("%s set to %s\n", key, strings.ToUpper(value))
+	}
+	return nil
+}
@@ -0,0 +1,221 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/pflag"
+	"github.com/the20100/meta-ad-library-cli/internal/api"
+	"github.com/the20100/meta-ad-library-cli/internal/cache"
+	"github.com/the20100/meta-ad-library-cli/internal/fx"
+)
+
+var (
+	// convertToFlag holds --convert-to; empty leaves spend in each ad's currency.
+	convertToFlag string
+	fxSourceFlag  string
+	fxFileFlag    string
+)
+
+// fxCacheTTL is how long rates fetched from a live endpoint are reused.
+const fxCacheTTL = 12 * time.Hour
+
+var fxHTTPClient = &http.Client{Timeout: 15 * time.Second}
+
+// addFXFlags registers the flags choosing where --convert-to gets its rates.
+func addFXFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&fxSourceFlag, "fx-source", "", "Exchange rates for --convert-to: builtin, file (with --fx-file), live, or an http(s) URL serving the same JSON format (default builtin)")
+	fs.StringVar(&fxFileFlag, "fx-file", "", "JSON file of exchange rates for --convert-to, e.g. {\"base\":\"USD\",\"rates\":{\"EUR\":0.92}}")
+}
+
+// warnedCurrencies remembers currencies already reported as unconvertible.
+var warnedCurrencies = map[string]bool{}
+
+// checkConvertTo validates and upper-cases --convert-to.
+func checkConvertTo() error {
+	if convertToFlag == "" {
+		return nil
+	}
+	convertToFlag = strings.ToUpper(convertToFlag)
+	if err := loadFXRates(); err != nil {
+		return err
+	}
+	if !fx.Known(convertToFlag) {
+		return usageErrorf("--convert-to: no exchange rate for %q", convertToFlag)
+	}
+	return nil
+}
+
+// loadFXRates switches the fx package to the rates picked by --fx-source.
+func loadFXRates() error {
+	source := strings.ToLower(fxSourceFlag)
+	if source == "" {
+		source = "builtin"
+		if fxFileFlag != "" {
+			source = "file"
+		}
+	}
+	if fxFileFlag != "" && source != "file" {
+		return usageErrorf("--fx-file needs --fx-source file")
+	}
+
+	var rates *fx.Rates
+	switch {
+	case source == "builtin":
+		return nil
+	case source == "file":
+		if fxFileFlag == "" {
+			return usageErrorf("--fx-source file needs --fx-file")
+		}
+		r, err := fx.ReadFile(fxFileFlag)
+		if err != nil {
+			return usageErrorf("--fx-file: %v", err)
+		}
+		rates = r
+	case source == "live":
+		r, err := liveRates(fx.DefaultURL)
+		if err != nil {
+			return err
+		}
+		rates = r
+	case strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://"):
+		// The URL is used as given: only the scheme is case-insensitive.
+		r, err := liveRates(fxSourceFlag)
+		if err != nil {
+			return err
+		}
+		rates = r
+	default:
+		return usageErrorf("--fx-source must be builtin, file, live, or an http(s) URL, got %q", fxSourceFlag)
+	}
+	slog.Debug(fmt.Sprintf("using %d exchange rates (base %s, dated %q)", len(rates.Rates), rates.Base, rates.Date))
+	fx.Use(rates)
+	return nil
+}
+
+// fxCache is the file caching the last rates fetched from a live endpoint.
+type fxCache struct {
+	URL   string    `json:"url"`
+	Rates *fx.Rates `json:"rates"`
+}
+
+// fxCachePath is fx-rates.json in the cache directory, so cache info and
+// cache clear cover it.
+func fxCachePath() (string, error) {
+	dir, err := cache.Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "fx-rates.json"), nil
+}
+
+// liveRates returns the rates served at url, reusing the cached copy for
+// fxCacheTTL. If the endpoint can't be reached, a stale cached copy is used
+// with a warning.
+func liveRates(url string) (*fx.Rates, error) {
+	path, err := fxCachePath()
+	if err != nil {
+		return nil, err
+	}
+	var cached *fx.Rates
+	if data, err := os.ReadFile(path); err == nil {
+		var c fxCache
+		if json.Unmarshal(data, &c) == nil && c.URL == url && c.Rates != nil {
+			cached = c.Rates
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		slog.Debug(fmt.Sprintf("reading %s: %v", path, err))
+	}
+	if cached != nil && time.Since(cached.FetchedAt) < fxCacheTTL {
+		slog.Debug(fmt.Sprintf("using exchange rates cached %s", cached.FetchedAt.Local().Format("2006-01-02 15:04")))
+		return cached, nil
+	}
+
+	slog.Debug(fmt.Sprintf("fetching exchange rates from %s", url))
+	r, err := fx.Fetch(fxHTTPClient, url)
+	if err != nil {
+		if cached != nil {
+			slog.Warn(fmt.Sprintf("could not refresh exchange rates (%v); using rates cached %s", err, cached.FetchedAt.Local().Format("2006-01-02 15:04")))
+			return cached, nil
+		}
+		return nil, fmt.Errorf("fetching exchange rates: %w", err)
+	}
+	if err := saveFXCache(path, &fxCache{URL: url, Rates: r}); err != nil {
+		slog.Warn(fmt.Sprintf("could not cache exchange rates: %v", err))
+	}
+	return r, nil
+}
+
+func saveFXCache(path string, c *fxCache) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	data, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// convertedSpend returns the ad's spend range in the --convert-to currency,
+// or false when there is nothing to convert or no rate for the ad's currency.
+func convertedSpend(a api.AdArchiveRecord) (*api.RangeValue, bool) {
+	if a.Spend == nil {
+		return nil, false
+	}
+	if strings.EqualFold(a.Currency, convertToFlag) {
+		return a.Spend, true
+	}
+	lo, ok := a.Spend.Lower()
+	if !ok {
+		return nil, false
+	}
+	if !fx.Known(a.Currency) {
+		if !warnedCurrencies[a.Currency] {
+			warnedCurrencies[a.Currency] = true
+			slog.Warn(fmt.Sprintf("no exchange rate for currency %q; its spend is left unconverted", a.Currency))
+		}
+		return nil, false
+	}
+	loC, _ := fx.Convert(lo, a.Currency, convertToFlag)
+	r := &api.RangeValue{LowerBound: fmt.Sprintf("%.0f", loC)}
+	// An open-ended range ("1M+") has no upper bound to convert.
+	if hi, ok := a.Spend.Upper(); ok {
+		hiC, _ := fx.Convert(hi, a.Currency, convertToFlag)
+		r.UpperBound = fmt.Sprintf("%.0f", hiC)
+	}
+	return r, true
+}
+
+// convertedRange is the spend_converted JSON value. Estimated is always true:
+// spend is only reported in ranges, and the rates are approximate.
+type convertedRange struct {
+	LowerBound string `json:"lower_bound"`
+	UpperBound string `json:"upper_bound,omitempty"`
+	Currency   string `json:"currency"`
+	Estimated  bool   `json:"estimated"`
+}
+
+// spendCell formats the SPEND column, marking converted values with "≈".
+func spendCell(a api.AdArchiveRecord) string {
+	if convertToFlag != "" {
+		if r, ok := convertedSpend(a); ok {
+			if strings.EqualFold(a.Currency, convertToFlag) {
+				return rangeCell(r) + " " + convertToFlag
+			}
+			return "≈" + rangeCell(r) + " " + convertToFlag
+		}
+	}
+	return withCurrency(rangeCell(a.Spend), a)
+}
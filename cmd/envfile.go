@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// defaultEnvFile is loaded from the working directory when --env-file is not given.
+const defaultEnvFile = ".env"
+
+// loadEnvFile sets variables from a dotenv-style file (KEY=VALUE lines, with
+// optional "export " prefix, quotes, blank lines, and # comments). Variables
+// already set in the real environment are left alone. Problems with the file
+// are only errors when it is required (--env-file); the implicit ./.env is
+// skipped when missing, and unreadable files or malformed lines only warn, so
+// a stray .env can't break every command.
+func loadEnvFile(path string, required bool) error {
+	f, err := os.Open(path)
+	if err != nil {
+		if required {
+			return fmt.Errorf("reading env file: %w", err)
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			slog.Warn(fmt.Sprintf("ignoring env file: %v", err))
+		}
+		return nil
+	}
+	defer f.Close()
+
+	sc := bufio.NewScanner(f)
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+		key, value, ok := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			if required {
+				return fmt.Errorf("%s:%d: expected KEY=VALUE", path, n)
+			}
+			slog.Warn(fmt.Sprintf("%s:%d: expected KEY=VALUE; line ignored", path, n))
+			continue
+		}
+		if _, set := os.LookupEnv(key); set {
+			continue
+		}
+		if err := os.Setenv(key, unquoteEnv(strings.TrimSpace(value))); err != nil {
+			return err
+		}
+	}
+	if err := sc.Err(); err != nil {
+		if required {
+			return fmt.Errorf("reading env file: %w", err)
+		}
+		slog.Warn(fmt.Sprintf("ignoring rest of env file %s: %v", path, err))
+	}
+	return nil
+}
+
+// unquoteEnv strips matching single or double quotes around v.
+func unquoteEnv(v string) string {
+	if len(v) >= 2 && (v[0] == '"' || v[0] == '\'') && v[len(v)-1] == v[0] {
+		return v[1 : len(v)-1]
+	}
+	return v
+}
@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/the20100/meta-ad-library-cli/internal/api"
+)
+
+// Process exit codes, so scripts can tell failure classes apart.
+const (
+	exitOK        = 0
+	exitFailure   = 1 // anything not classified below
+	exitAuth      = 2 // not authenticated, the token was rejected, or it lacks Ad Library access
+	exitRateLimit = 3 // Meta throttling (codes 4, 17, 613)
+	exitUsage     = 4 // bad flags, arguments, or flag values
+)
+
+// usageError marks an error caused by invalid user input.
+type usageError struct{ error }
+
+func (e usageError) Unwrap() error { return e.error }
+
+// usageErrorf formats a usageError.
+func usageErrorf(format string, args ...any) error {
+	return usageError{fmt.Errorf(format, args...)}
+}
+
+// authError marks a missing or unusable token.
+type authError struct{ error }
+
+func (e authError) Unwrap() error { return e.error }
+
+// authErrorf formats an authError.
+func authErrorf(format string, args ...any) error {
+	return authError{fmt.Errorf(format, args...)}
+}
+
+// exitCode maps err to a process exit code. preRun reports whether command
+// execution got as far as PersistentPreRunE; errors before that point come
+// from flag parsing or argument validation and are usage errors.
+func exitCode(err error, preRun bool) int {
+	if err == nil {
+		return exitOK
+	}
+	if !preRun {
+		return exitUsage
+	}
+
+	var ue usageError
+	var ae authError
+	var metaErr *api.MetaError
+	switch {
+	case errors.As(err, &ue):
+		return exitUsage
+	case errors.As(err, &ae):
+		return exitAuth
+	case errors.As(err, &metaErr) && metaErr.IsRateLimit():
+		return exitRateLimit
+	case errors.As(err, &metaErr) && (metaErr.IsAuth() || metaErr.IsAdLibraryAccess()):
+		return exitAuth
+	}
+	return exitFailure
+}
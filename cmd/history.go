@@ -0,0 +1,152 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/the20100/meta-ad-library-cli/internal/api"
+	"github.com/the20100/meta-ad-library-cli/internal/output"
+)
+
+var adGetHistory bool
+
+// historyFields are the fields ad get --history adds to the request: the
+// delivery dates for the timeline, and the delivery breakdowns Meta returns
+// for EU and political/issue ads.
+var historyFields = []string{
+	"ad_creation_time", "ad_delivery_start_time", "ad_delivery_stop_time",
+	"delivery_by_region", "eu_total_reach", "age_country_gender_reach_breakdown",
+}
+
+func init() {
+	adGetCmd.Flags().BoolVar(&adGetHistory, "history", false, "Also request and show the ad's delivery timeline and reach breakdowns (EU and political/issue ads)")
+}
+
+// withHistoryFields adds historyFields to a comma-separated field list.
+func withHistoryFields(fields string) string {
+	list := strings.Split(fields, ",")
+	for _, f := range historyFields {
+		if !strings.Contains(","+fields+",", ","+f+",") {
+			list = append(list, f)
+		}
+	}
+	return strings.Join(list, ",")
+}
+
+// writeDeliveryHistory renders what Meta reports about an ad's delivery over
+// time: its timeline, then, where available, delivery by region and EU reach
+// by country and by age and gender. The Ad Library has no daily series, so
+// this is as close to a history as the API gets.
+func writeDeliveryHistory(w io.Writer, a api.AdArchiveRecord) {
+	fmt.Fprintln(w, "\nDelivery History:")
+	stopped := output.FormatTime(a.AdDeliveryStopTime)
+	if a.AdDeliveryStopTime == "" {
+		stopped = "still running"
+	}
+	fmt.Fprintf(w, "  %-10s %s\n", "Created", output.FormatTime(a.AdCreationTime))
+	fmt.Fprintf(w, "  %-10s %s\n", "Started", output.FormatTime(a.AdDeliveryStartTime))
+	fmt.Fprintf(w, "  %-10s %s\n", "Stopped", stopped)
+	if days, ok := deliveryDays(a, time.Now()); ok {
+		fmt.Fprintf(w, "  %-10s %s day(s)\n", "Delivered", output.FormatNumber(fmt.Sprint(days)))
+	}
+	if a.EUTotalReach > 0 {
+		fmt.Fprintf(w, "  %-10s %s\n", "EU reach", output.FormatNumber(fmt.Sprint(a.EUTotalReach)))
+	}
+
+	bars := output.IsTerminal()
+	if len(a.DeliveryByRegion) > 0 {
+		fmt.Fprintln(w, "\nDelivery by Region:")
+		peak := 0.0
+		for _, d := range a.DeliveryByRegion {
+			peak = max(peak, d.Percentage)
+		}
+		for _, d := range a.DeliveryByRegion {
+			if bars {
+				fmt.Fprintf(w, "  %-30s %-30s %.1f%%\n", d.Region, output.Bar(d.Percentage, peak, distributionBarWidth), d.Percentage)
+			} else {
+				fmt.Fprintf(w, "  %-30s %.1f%%\n", d.Region, d.Percentage)
+			}
+		}
+	}
+
+	if len(a.AgeCountryGenderReach) == 0 {
+		if len(a.DeliveryByRegion) == 0 && a.EUTotalReach == 0 {
+			fmt.Fprintln(w, "\nMeta returned no delivery breakdowns; they are only published for EU and political/issue ads.")
+		}
+		return
+	}
+
+	// Reach by country, largest first.
+	type countryTotal struct {
+		country string
+		reach   int64
+	}
+	var countries []countryTotal
+	byAge := map[string]*api.AgeGenderReach{}
+	var ages []string
+	for _, c := range a.AgeCountryGenderReach {
+		t := countryTotal{country: c.Country}
+		for _, b := range c.AgeGenderBreakdowns {
+			t.reach += b.Total()
+			sum, ok := byAge[b.AgeRange]
+			if !ok {
+				sum = &api.AgeGenderReach{AgeRange: b.AgeRange}
+				byAge[b.AgeRange] = sum
+				ages = append(ages, b.AgeRange)
+			}
+			sum.Male += b.Male
+			sum.Female += b.Female
+			sum.Unknown += b.Unknown
+		}
+		countries = append(countries, t)
+	}
+	sort.SliceStable(countries, func(i, j int) bool { return countries[i].reach > countries[j].reach })
+
+	fmt.Fprintln(w, "\nEU Reach by Country:")
+	peak := 0.0
+	for _, c := range countries {
+		peak = max(peak, float64(c.reach))
+	}
+	for _, c := range countries {
+		reach := output.FormatNumber(fmt.Sprint(c.reach))
+		if bars {
+			fmt.Fprintf(w, "  %-6s %-30s %s\n", c.country, output.Bar(float64(c.reach), peak, distributionBarWidth), reach)
+		} else {
+			fmt.Fprintf(w, "  %-6s %s\n", c.country, reach)
+		}
+	}
+
+	fmt.Fprintln(w, "\nEU Reach by Age and Gender:")
+	sort.Strings(ages)
+	n := func(v int64) string { return output.FormatNumber(fmt.Sprint(v)) }
+	fmt.Fprintf(w, "  %-8s %12s %12s %12s %12s\n", "AGE", "MALE", "FEMALE", "UNKNOWN", "TOTAL")
+	for _, age := range ages {
+		r := byAge[age]
+		fmt.Fprintf(w, "  %-8s %12s %12s %12s %12s\n", age, n(r.Male), n(r.Female), n(r.Unknown), n(r.Total()))
+	}
+}
+
+// deliveryDays is the number of calendar days the ad delivered, counting
+// both the start and stop day, up to now for a running ad.
+func deliveryDays(a api.AdArchiveRecord, now time.Time) (int, bool) {
+	if len(a.AdDeliveryStartTime) < 10 {
+		return 0, false
+	}
+	start, err := time.Parse(time.DateOnly, a.AdDeliveryStartTime[:10])
+	if err != nil {
+		return 0, false
+	}
+	end := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	if len(a.AdDeliveryStopTime) >= 10 {
+		if end, err = time.Parse(time.DateOnly, a.AdDeliveryStopTime[:10]); err != nil {
+			return 0, false
+		}
+	}
+	if end.Before(start) {
+		return 0, false
+	}
+	return int(end.Sub(start).Hours()/24) + 1, true
+}
@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// validInputFormats are the accepted --input-format values; auto picks json
+// when the input starts with "[" and jsonl otherwise.
+var validInputFormats = []string{"auto", "json", "jsonl"}
+
+// maxInputLine bounds a single NDJSON line (one ad).
+const maxInputLine = 16 << 20
+
+// readAdsFile reads saved ads from path ("-" = stdin), either a JSON array
+// (search --json) or one object per line (export ndjson). Files ending in .gz
+// are decompressed.
+func readAdsFile(path, format string) ([]json.RawMessage, error) {
+	format = strings.ToLower(format)
+	if err := checkChoice("input-format", format, validInputFormats); err != nil {
+		return nil, err
+	}
+
+	var r io.Reader = os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		r = f
+	}
+	if strings.HasSuffix(path, ".gz") {
+		gz, err := gzip.NewReader(r)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		defer gz.Close()
+		r = gz
+	}
+	br := bufio.NewReader(r)
+
+	if format == "auto" {
+		format = "jsonl"
+		for {
+			b, err := br.ReadByte()
+			if err != nil {
+				break // empty input: no ads
+			}
+			if b == ' ' || b == '\t' || b == '\r' || b == '\n' {
+				continue
+			}
+			if b == '[' {
+				format = "json"
+			}
+			br.UnreadByte() //nolint:errcheck
+			break
+		}
+	}
+
+	if format == "json" {
+		var items []json.RawMessage
+		if err := json.NewDecoder(br).Decode(&items); err != nil {
+			return nil, fmt.Errorf("%s: expected a JSON array of ads: %w", path, err)
+		}
+		return items, nil
+	}
+
+	var items []json.RawMessage
+	sc := bufio.NewScanner(br)
+	sc.Buffer(make([]byte, 64*1024), maxInputLine)
+	for line := 1; sc.Scan(); line++ {
+		b := bytes.TrimSpace(sc.Bytes())
+		if len(b) == 0 {
+			continue
+		}
+		if !json.Valid(b) {
+			return nil, fmt.Errorf("%s:%d: invalid JSON", path, line)
+		}
+		items = append(items, json.RawMessage(bytes.Clone(b)))
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return items, nil
+}
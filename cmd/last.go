@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/the20100/meta-ad-library-cli/internal/api"
+	"github.com/the20100/meta-ad-library-cli/internal/cache"
+	"github.com/the20100/meta-ad-library-cli/internal/output"
+)
+
+var lastFormat string
+
+var validLastFormats = []string{"table", "json", "csv"}
+
+var lastCmd = &cobra.Command{
+	Use:   "last",
+	Short: "Re-display the results saved by the last --save-last run",
+	Long: `Re-renders the result set saved by search or page ads with --save-last,
+without calling the API.
+
+--format picks the output: table, json, or csv. By default it follows the
+usual rule (table on a terminal, JSON when piped or with --json).
+
+Examples:
+  meta-adlib search --query "shoes" --country US --limit 0 --save-last
+  meta-adlib last
+  meta-adlib last --format csv > ads.csv`,
+	Args: cobra.NoArgs,
+	RunE: runLast,
+}
+
+func init() {
+	lastCmd.Flags().StringVar(&lastFormat, "format", "", "Output format: table, json, or csv")
+	rootCmd.AddCommand(lastCmd)
+}
+
+func runLast(cmd *cobra.Command, args []string) error {
+	format := strings.ToLower(lastFormat)
+	if format == "" {
+		format = "table"
+		if output.IsJSON(cmd) {
+			format = "json"
+		}
+	} else if err := checkChoice("format", format, validLastFormats); err != nil {
+		return err
+	}
+
+	last, err := cache.LoadLast()
+	if err != nil {
+		return err
+	}
+
+	switch format {
+	case "json":
+		items, err := adsJSON(last.Items)
+		if err != nil {
+			return err
+		}
+		if items == nil {
+			items = []json.RawMessage{}
+		}
+		return output.PrintJSON(items, output.IsPretty(cmd))
+	case "csv":
+		ads := parseAds(last.Items)
+		return writeAdsCSV(output.Out, ads)
+	}
+
+	ads := parseAds(last.Items)
+	if len(ads) == 0 {
+		fmt.Println("no ads in the saved results")
+		return nil
+	}
+	printAdsTable(ads, nil)
+	fmt.Printf("\n%d ad(s) from %s, saved %s\n", len(ads), last.Command, last.SavedAt.Local().Format("2006-01-02 15:04"))
+	printAdsSummary(ads)
+	return nil
+}
+
+// saveLast stores items as the last result set for the last command. Failures
+// only warn: the results have already been fetched and are still printed.
+func saveLast(cmd *cobra.Command, items []json.RawMessage) {
+	err := cache.SaveLast(&cache.Last{SavedAt: time.Now(), Command: cmd.CommandPath(), Items: items})
+	if err != nil {
+		slog.Warn(fmt.Sprintf("could not save results: %v", err))
+	}
+}
+
+// adsCSVHeader lists the columns written by writeAdsCSV.
+var adsCSVHeader = []string{
+	"id", "page_id", "page_name", "ad_creation_time", "ad_delivery_start_time", "ad_delivery_stop_time",
+	"spend_lower", "spend_upper", "impressions_lower", "impressions_upper", "currency",
+	"publisher_platforms", "languages", "ad_creative_bodies", "ad_snapshot_url",
+}
+
+// writeAdsCSV writes ads as CSV with a header row. List fields are joined
+// with "|".
+func writeAdsCSV(w io.Writer, ads []api.AdArchiveRecord) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(adsCSVHeader); err != nil {
+		return err
+	}
+	bound := func(r *api.RangeValue, upper bool) string {
+		if r == nil {
+			return ""
+		}
+		if upper {
+			return r.UpperBound
+		}
+		return r.LowerBound
+	}
+	for _, a := range ads {
+		if err := cw.Write([]string{
+			a.ID, a.PageID, a.PageName, a.AdCreationTime, a.AdDeliveryStartTime, a.AdDeliveryStopTime,
+			bound(a.Spend, false), bound(a.Spend, true), bound(a.Impressions, false), bound(a.Impressions, true), a.Currency,
+			strings.Join(a.PublisherPlatforms, "|"), strings.Join(a.Languages, "|"),
+			strings.Join(a.AdCreativeBodies, "|"), a.AdSnapshotURL,
+		}); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/the20100/meta-ad-library-cli/internal/api"
+)
+
+// serveMetrics are the metrics exposed on serve's /metrics endpoint, along
+// with the Go runtime and process metrics. The API client reports to them as
+// an api.Observer.
+type serveMetrics struct {
+	registry     *prometheus.Registry
+	apiRequests  prometheus.Counter
+	apiErrors    *prometheus.CounterVec
+	rateWarnings prometheus.Counter
+	apiLatency   prometheus.Histogram
+	served       *prometheus.CounterVec
+}
+
+// apiLatencyBuckets are the upper bounds, in seconds, of the API latency
+// histogram. Graph API calls usually take 0.2–2s; paging through large
+// result sets can take longer per page.
+var apiLatencyBuckets = []float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60}
+
+func newServeMetrics() *serveMetrics {
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(collectors.NewGoCollector(), collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
+	f := promauto.With(reg)
+	return &serveMetrics{
+		registry: reg,
+		apiRequests: f.NewCounter(prometheus.CounterOpts{
+			Name: "meta_adlib_api_requests_total",
+			Help: "Graph API requests made, retries included.",
+		}),
+		apiErrors: f.NewCounterVec(prometheus.CounterOpts{
+			Name: "meta_adlib_api_errors_total",
+			Help: "Graph API requests that failed, by kind: rate_limit, auth, meta, http, network, timeout, other.",
+		}, []string{"kind"}),
+		rateWarnings: f.NewCounter(prometheus.CounterOpts{
+			Name: "meta_adlib_api_rate_limit_warnings_total",
+			Help: "Responses whose X-App-Usage crossed the --rate-warn-at threshold.",
+		}),
+		apiLatency: f.NewHistogram(prometheus.HistogramOpts{
+			Name:    "meta_adlib_api_request_duration_seconds",
+			Help:    "Graph API request latency.",
+			Buckets: apiLatencyBuckets,
+		}),
+		served: f.NewCounterVec(prometheus.CounterOpts{
+			Name: "meta_adlib_http_requests_total",
+			Help: "Requests served, by endpoint and status code.",
+		}, []string{"endpoint", "code"}),
+	}
+}
+
+// serveStats collects serve's metrics; PersistentPreRunE attaches it to the
+// client when serve runs.
+var serveStats = newServeMetrics()
+
+func (m *serveMetrics) RequestDone(took time.Duration, err error) {
+	m.apiRequests.Inc()
+	m.apiLatency.Observe(took.Seconds())
+	if err != nil {
+		m.apiErrors.WithLabelValues(apiErrorKind(err)).Inc()
+	}
+}
+
+func (m *serveMetrics) RateLimitWarned() {
+	m.rateWarnings.Inc()
+}
+
+// apiErrorKind classifies a failed request for meta_adlib_api_errors_total.
+func apiErrorKind(err error) string {
+	var metaErr *api.MetaError
+	var httpErr *api.HTTPError
+	var urlErr *url.Error
+	switch {
+	case errors.As(err, &metaErr) && metaErr.IsRateLimit():
+		return "rate_limit"
+	case errors.As(err, &metaErr) && metaErr.IsAuth(), errors.As(err, new(*api.AdLibraryAccessError)):
+		return "auth"
+	case errors.As(err, &metaErr):
+		return "meta"
+	case errors.As(err, &httpErr):
+		return "http"
+	case errors.Is(err, context.DeadlineExceeded):
+		return "timeout"
+	case errors.As(err, &urlErr):
+		if urlErr.Timeout() {
+			return "timeout"
+		}
+		return "network"
+	}
+	return "other"
+}
+
+// handler serves the metrics in the Prometheus exposition format.
+func (m *serveMetrics) handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// counted wraps h to count its responses under endpoint.
+func (m *serveMetrics) counted(endpoint string, h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		h(rec, r)
+		m.served.WithLabelValues(endpoint, strconv.Itoa(rec.status)).Inc()
+	}
+}
+
+// statusRecorder remembers the status code written through it.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(code int) {
+	r.status = code
+	r.ResponseWriter.WriteHeader(code)
+}
@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/the20100/meta-ad-library-cli/internal/api"
+)
+
+func TestAPIErrorKind(t *testing.T) {
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{&api.MetaError{Code: 17}, "rate_limit"},
+		{&api.MetaError{Code: 190}, "auth"},
+		{&api.MetaError{Code: 100}, "meta"},
+		{&api.HTTPError{StatusCode: 502}, "http"},
+		{fmt.Errorf("page 2: %w", context.DeadlineExceeded), "timeout"},
+		{&url.Error{Op: "Get", URL: "https://graph.facebook.com", Err: errors.New("connection refused")}, "network"},
+		{errors.New("boom"), "other"},
+	}
+	for _, tt := range tests {
+		if got := apiErrorKind(tt.err); got != tt.want {
+			t.Errorf("apiErrorKind(%v) = %q; want %q", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestServeMetrics(t *testing.T) {
+	m := newServeMetrics()
+	m.RequestDone(300*time.Millisecond, nil)
+	m.RequestDone(2*time.Second, &api.MetaError{Code: 4})
+	m.RateLimitWarned()
+	teapot := m.counted("search", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})
+	teapot(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/search", nil))
+
+	rec := httptest.NewRecorder()
+	m.handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body := rec.Body.String()
+	for _, want := range []string{
+		"meta_adlib_api_requests_total 2\n",
+		`meta_adlib_api_errors_total{kind="rate_limit"} 1` + "\n",
+		"meta_adlib_api_rate_limit_warnings_total 1\n",
+		`meta_adlib_api_request_duration_seconds_bucket{le="0.5"} 1` + "\n",
+		"meta_adlib_api_request_duration_seconds_count 2\n",
+		`meta_adlib_http_requests_total{code="418",endpoint="search"} 1` + "\n",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("/metrics lacks %q", want)
+		}
+	}
+}
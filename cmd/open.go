@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+
+	"github.com/spf13/cobra"
+	"github.com/the20100/meta-ad-library-cli/internal/api"
+)
+
+var (
+	adOpenPermalink bool
+	adOpenPrint     bool
+)
+
+var adOpenCmd = &cobra.Command{
+	Use:   "open <ad_archive_id>",
+	Short: "Open an ad's snapshot in the default browser",
+	Long: `Looks up the ad's ad_snapshot_url and opens it in the default browser, with
+the access token added since Meta only renders snapshots for a valid token.
+The token is then part of the URL and so of the browser history.
+
+--permalink opens the ad's public Ad Library page instead: it needs no token
+and no API call, and is the link to share.
+
+The browser is $BROWSER when set, else open (macOS), xdg-open (Linux and
+other Unixes) or the URL handler (Windows). --print prints the URL instead,
+for machines without a browser.
+
+Examples:
+  meta-adlib ad open 123456789012345
+  meta-adlib ad open 123456789012345 --permalink
+  meta-adlib ad open 123456789012345 --permalink --print`,
+	Args: cobra.ExactArgs(1),
+	RunE: runAdOpen,
+}
+
+func init() {
+	adOpenCmd.Flags().BoolVar(&adOpenPermalink, "permalink", false, "Open the public Ad Library page instead of the snapshot (no token needed)")
+	adOpenCmd.Flags().BoolVar(&adOpenPrint, "print", false, "Print the URL instead of opening it")
+
+	adCmd.AddCommand(adOpenCmd)
+}
+
+func runAdOpen(cmd *cobra.Command, args []string) error {
+	id := args[0]
+	var target string
+	if adOpenPermalink {
+		target = (&api.AdArchiveRecord{ID: id}).Permalink()
+	} else {
+		ad, _, err := client.GetAd(id, []string{"id", "ad_snapshot_url"})
+		if err != nil {
+			return err
+		}
+		if ad.AdSnapshotURL == "" {
+			return fmt.Errorf("ad %s has no ad_snapshot_url — try --permalink", id)
+		}
+		if target, err = client.SnapshotURL(ad.AdSnapshotURL); err != nil {
+			return fmt.Errorf("invalid ad_snapshot_url: %w", err)
+		}
+	}
+
+	if adOpenPrint {
+		fmt.Println(target)
+		return nil
+	}
+	if err := openBrowser(target); err != nil {
+		return fmt.Errorf("opening a browser: %w (use --print to get the URL)", err)
+	}
+	return nil
+}
+
+// openBrowser opens target with $BROWSER or the OS's URL handler, without
+// waiting for the browser to exit.
+func openBrowser(target string) error {
+	var c *exec.Cmd
+	switch b := os.Getenv("BROWSER"); {
+	case b != "":
+		c = exec.Command(b, target)
+	case runtime.GOOS == "darwin":
+		c = exec.Command("open", target)
+	case runtime.GOOS == "windows":
+		// "start" would need cmd.exe, which splits URLs at "&".
+		c = exec.Command("rundll32", "url.dll,FileProtocolHandler", target)
+	default:
+		c = exec.Command("xdg-open", target)
+	}
+	if err := c.Start(); err != nil {
+		return err
+	}
+	return c.Process.Release()
+}
@@ -0,0 +1,184 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/the20100/meta-ad-library-cli/internal/output"
+)
+
+var (
+	// outDirFlag holds --out-dir; empty disables archiving.
+	outDirFlag string
+	// alsoJSONFlag and alsoCSVFlag hold --also-json and --also-csv: files to
+	// write the results to besides printing them.
+	alsoJSONFlag string
+	alsoCSVFlag  string
+)
+
+// maxSlugLen caps the query part of an archive file name.
+const maxSlugLen = 40
+
+func addOutDirFlag(fs *pflag.FlagSet) {
+	fs.StringVar(&outDirFlag, "out-dir", "", "Also write the results to a new timestamped file in this directory (.json in JSON mode, else .csv)")
+}
+
+func addAlsoFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&alsoJSONFlag, "also-json", "", "Also write the results as a JSON array to this file, whatever the output format")
+	fs.StringVar(&alsoCSVFlag, "also-csv", "", "Also write the results as CSV to this file, whatever the output format")
+}
+
+// writeAlsoFiles writes items to the --also-json and --also-csv files,
+// replacing any existing file, so one run both prints a table and saves
+// structured data. A failed file doesn't stop the other being written;
+// callers print the results before returning the error.
+func writeAlsoFiles(items []json.RawMessage) error {
+	var errs []error
+	for _, f := range []struct {
+		flag, path string
+		write      func(io.Writer, []json.RawMessage) error
+	}{
+		{"also-json", alsoJSONFlag, writeAdsJSON},
+		{"also-csv", alsoCSVFlag, func(w io.Writer, items []json.RawMessage) error { return writeAdsCSV(w, parseAds(items)) }},
+	} {
+		if f.path == "" {
+			continue
+		}
+		if err := writeFile(f.path, items, f.write); err != nil {
+			errs = append(errs, fmt.Errorf("--%s: %w", f.flag, err))
+			continue
+		}
+		slog.Info(fmt.Sprintf("results written to %s", f.path))
+	}
+	return errors.Join(errs...)
+}
+
+func writeFile(path string, items []json.RawMessage, write func(io.Writer, []json.RawMessage) error) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := write(f, items); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// writeAdsJSON writes items to w as one JSON array, as JSON output would
+// print them.
+func writeAdsJSON(w io.Writer, items []json.RawMessage) error {
+	raw, err := adsJSON(items)
+	if err != nil {
+		return err
+	}
+	if raw == nil {
+		raw = []json.RawMessage{}
+	}
+	return json.NewEncoder(w).Encode(raw)
+}
+
+// archiveResults writes items to a new file in --out-dir named
+// <command>-<query>-<timestamp>.<ext>, as JSON when output is JSON and as CSV
+// otherwise. Failures only warn: the results are still printed.
+func archiveResults(cmd *cobra.Command, query string, items []json.RawMessage) {
+	if outDirFlag == "" {
+		return
+	}
+	path, err := writeArchive(cmd, query, items)
+	if err != nil {
+		slog.Warn(fmt.Sprintf("could not write results to --out-dir: %v", err))
+		return
+	}
+	slog.Info(fmt.Sprintf("results written to %s", path))
+}
+
+func writeArchive(cmd *cobra.Command, query string, items []json.RawMessage) (string, error) {
+	ext := "csv"
+	if output.IsJSON(cmd) {
+		ext = "json"
+	}
+	if err := os.MkdirAll(outDirFlag, 0o755); err != nil {
+		return "", err
+	}
+	f, path, err := createArchiveFile(archiveName(cmd, query, time.Now()), ext)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	if ext == "csv" {
+		err = writeAdsCSV(f, parseAds(items))
+	} else {
+		err = writeAdsJSON(f, items)
+	}
+	if err != nil {
+		return "", err
+	}
+	return path, f.Close()
+}
+
+// createArchiveFile creates <out-dir>/<base>.<ext>, adding -2, -3, ... to
+// base rather than overwrite a file from a run in the same second.
+func createArchiveFile(base, ext string) (*os.File, string, error) {
+	for n := 1; ; n++ {
+		name := base
+		if n > 1 {
+			name = fmt.Sprintf("%s-%d", base, n)
+		}
+		path := filepath.Join(outDirFlag, name+"."+ext)
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+		if errors.Is(err, os.ErrExist) {
+			continue
+		}
+		return f, path, err
+	}
+}
+
+// archiveName is the file name, without extension, for results of cmd run
+// with query at t: e.g. "page-ads-123456789-20261016-153000". The query part
+// is left out when it has no usable characters.
+func archiveName(cmd *cobra.Command, query string, t time.Time) string {
+	parts := []string{strings.ReplaceAll(strings.TrimPrefix(cmd.CommandPath(), cmd.Root().Name()+" "), " ", "-")}
+	if slug := slugify(query); slug != "" {
+		parts = append(parts, slug)
+	}
+	parts = append(parts, t.Format("20060102-150405"))
+	return strings.Join(parts, "-")
+}
+
+// slugify reduces s to lowercase letters and digits, with runs of anything
+// else (path separators included) collapsed to one "-", cut to maxSlugLen
+// characters.
+func slugify(s string) string {
+	var b strings.Builder
+	n := 0
+	dash := false
+	for _, r := range strings.ToLower(s) {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			dash = true
+			continue
+		}
+		if dash && n > 0 {
+			b.WriteByte('-')
+			n++
+		}
+		b.WriteRune(r)
+		n++
+		dash = false
+		if n >= maxSlugLen {
+			break
+		}
+	}
+	return b.String()
+}
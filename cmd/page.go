@@ -3,7 +3,11 @@ package cmd
 import (
 	"encoding/json"
 	"fmt"
+	"log/slog"
+	"maps"
 	"net/url"
+	"strconv"
+	"strings"
 
 	"github.com/spf13/cobra"
 	"github.com/the20100/meta-ad-library-cli/internal/api"
@@ -11,98 +15,390 @@ import (
 )
 
 var (
-	pageCountries []string
-	pageAdType    string
-	pageStatus    string
-	pageLimit     int
-	pageDateMin   string
-	pageDateMax   string
+	pageCountries  []string
+	pageAdType     string
+	pageStatus     string
+	pageActiveOnly bool
+	pageAllStatus  bool
+	pageLimit      int
+	pageDateMin    string
+	pageDateMax    string
+	pageAllFields  bool
+	pageDryRun     bool
+	pageRawParams  []string
+	pageURLsOnly   bool
+	pageSaveLast   bool
+	pageFirstOnly  bool
+	pageByStatus   bool
+	pageUnmask     bool
+	pagePerCountry bool
+
+	pageSearchLimit int
 )
 
+// maxPageIDs is the most page IDs /ads_archive accepts in search_page_ids.
+const maxPageIDs = 10
+
 var pageCmd = &cobra.Command{
 	Use:   "page",
 	Short: "Browse ads by Facebook Page",
 }
 
 var pageAdsCmd = &cobra.Command{
-	Use:   "ads <page_id>",
-	Short: "List all ads for a specific Facebook Page",
-	Long: `Fetches all ads associated with a given Facebook Page ID.
+	Use:   "ads <page_id> [page_id...]",
+	Short: "List all ads for one or more Facebook Pages",
+	Long: `Fetches all ads associated with the given Facebook Page IDs (up to 10,
+the API's limit for search_page_ids).
 
 This is equivalent to searching by --page-id but as a dedicated sub-command
-with a friendlier interface for page-focused research.
+with a friendlier interface for page-focused research. With several pages,
+a per-page count is printed under the table.
 
 Examples:
   meta-adlib page ads 123456789 --country US
+  meta-adlib page ads 111 222 333 --country US
   meta-adlib page ads 123456789 --country DE --status ACTIVE
-  meta-adlib page ads 123456789 --country US --type POLITICAL_AND_ISSUE_ADS --limit 100 --json`,
-	Args: cobra.ExactArgs(1),
+  meta-adlib page ads 123456789 --country DE --group-by-status
+  meta-adlib page ads 123456789 --country DE --country FR --country IT --per-country --limit 0
+  meta-adlib page ads 123456789 --country US --type POLITICAL_AND_ISSUE_ADS --limit 100 --json
+  meta-adlib page ads 123456789 --country US --snapshot-urls > review.txt`,
+	Args: cobra.RangeArgs(1, maxPageIDs),
 	RunE: runPageAds,
 }
 
+var pageSearchCmd = &cobra.Command{
+	Use:   "search <name>",
+	Short: "Find Facebook Pages by name, to get their IDs",
+	Long: `Looks up Facebook Pages matching a name via /pages/search and lists their
+IDs, names, categories and verification status, so the right page ID can be
+picked for page ads or --page-id. Several words are searched as one name.
+
+Examples:
+  meta-adlib page search "Acme"
+  meta-adlib page search Acme Shoes --limit 5
+  meta-adlib page search "Acme" --json | jq -r '.[0].id'`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runPageSearch,
+}
+
 func init() {
 	pageAdsCmd.Flags().StringArrayVar(&pageCountries, "country", nil, "Country code(s) (ISO 3166). Repeatable.")
 	pageAdsCmd.Flags().StringVar(&pageAdType, "type", "ALL", "Ad type: ALL or POLITICAL_AND_ISSUE_ADS")
-	pageAdsCmd.Flags().StringVar(&pageStatus, "status", "ALL", "Ad active status: ALL or ACTIVE")
+	pageAdsCmd.Flags().StringVar(&pageStatus, "status", "ALL", "Ad active status: ALL, ACTIVE, or INACTIVE")
+	pageAdsCmd.Flags().BoolVar(&pageActiveOnly, "active-only", false, "Only currently running ads (same as --status ACTIVE)")
+	pageAdsCmd.Flags().BoolVar(&pageAllStatus, "include-inactive", false, "Running and stopped ads, the default (same as --status ALL)")
 	pageAdsCmd.Flags().IntVar(&pageLimit, "limit", 25, "Maximum number of results (0 = fetch all pages)")
-	pageAdsCmd.Flags().StringVar(&pageDateMin, "since", "", "Minimum delivery start date (YYYY-MM-DD)")
-	pageAdsCmd.Flags().StringVar(&pageDateMax, "until", "", "Maximum delivery start date (YYYY-MM-DD)")
+	pageAdsCmd.Flags().StringVar(&pageDateMin, "since", "", "Minimum delivery start date (YYYY-MM-DD, today, yesterday, 30d, 4w)")
+	pageAdsCmd.Flags().StringVar(&pageDateMax, "until", "", "Maximum delivery start date (YYYY-MM-DD, today, yesterday, 30d, 4w)")
+	pageAdsCmd.Flags().BoolVar(&pageAllFields, "all-fields", false, "Request every documented /ads_archive field")
+	pageAdsCmd.Flags().StringArrayVar(&pageRawParams, "param", nil, "Raw Graph API query parameter as key=value, overriding built-in ones. Repeatable.")
+	pageAdsCmd.Flags().StringSliceVar(&columnsFlag, "columns", nil, "Comma-separated table columns (see search --help); derived ones like spend_per_impression are also added to JSON")
+	pageAdsCmd.Flags().StringVar(&convertToFlag, "convert-to", "", "Convert spend to this currency (e.g. USD) with approximate exchange rates (see --fx-source), for display and totals")
+	addFXFlags(pageAdsCmd.Flags())
+	addSpendTierFlags(pageAdsCmd.Flags())
+	pageAdsCmd.Flags().BoolVar(&pageFirstOnly, "first-page-only", false, "Make exactly one request: a single page of up to 2000 ads (or an explicit --limit), never following paging cursors")
+	pageAdsCmd.Flags().BoolVar(&pageUnmask, "unmask-removed", false, "Show the content of ads removed for violating standards (requires researcher access from Meta)")
+	pageAdsCmd.Flags().BoolVar(&pagePerCountry, "per-country", false, "Run the query once per --country and report ad counts and spend by country")
+	pageAdsCmd.Flags().BoolVar(&pageByStatus, "group-by-status", false, "Print active and inactive ads as separate tables, each with its own spend subtotal")
+	pageAdsCmd.Flags().BoolVar(&pageSaveLast, "save-last", false, "Save the results for re-display with the last command")
+	pageAdsCmd.Flags().BoolVar(&pageURLsOnly, "snapshot-urls", false, "Print only each ad's snapshot URL, one per line")
+	addWithTokenFlag(pageAdsCmd.Flags())
+	pageAdsCmd.Flags().BoolVar(&pageDryRun, "dry-run", false, "Print the request URL (token redacted) without calling the API")
+	pageAdsCmd.MarkFlagsMutuallyExclusive("per-country", "snapshot-urls")
+	pageAdsCmd.MarkFlagsMutuallyExclusive("per-country", "save-last")
+	addOutDirFlag(pageAdsCmd.Flags())
+	addAlsoFlags(pageAdsCmd.Flags())
+	pageAdsCmd.MarkFlagsMutuallyExclusive("per-country", "group-by-status")
+	pageAdsCmd.MarkFlagsMutuallyExclusive("per-country", "out-dir")
+	pageAdsCmd.MarkFlagsMutuallyExclusive("per-country", "also-json")
+	pageAdsCmd.MarkFlagsMutuallyExclusive("per-country", "also-csv")
+
+	pageSearchCmd.Flags().IntVar(&pageSearchLimit, "limit", 25, "Maximum number of pages to list (0 = all returned)")
 
 	pageCmd.AddCommand(pageAdsCmd)
+	pageCmd.AddCommand(pageSearchCmd)
 	rootCmd.AddCommand(pageCmd)
 }
 
 func runPageAds(cmd *cobra.Command, args []string) error {
-	pageID := args[0]
+	pageIDs := args
+	pageLimit = withDefaultLimit(cmd, pageLimit)
+	if pageFirstOnly && !cmd.Flags().Changed("limit") {
+		pageLimit = 0 // a full page
+	}
+	if err := checkColumns(); err != nil {
+		return err
+	}
+	if err := checkConvertTo(); err != nil {
+		return err
+	}
+	if err := checkSpendTiers(); err != nil {
+		return err
+	}
 
-	if len(pageCountries) == 0 {
-		return fmt.Errorf("at least one --country is required (e.g. --country US)")
+	countries := withDefaultCountry(pageCountries)
+	if len(countries) == 0 {
+		return errNoCountry
+	}
+	adType, err := normalizeUpper("type", pageAdType, validAdTypes)
+	if err != nil {
+		return err
+	}
+	status, err := normalizeStatus(pageStatus, pageActiveOnly, pageAllStatus)
+	if err != nil {
+		return err
 	}
 
 	params := url.Values{}
-	params.Set("fields", defaultFields)
-	params.Set("ad_type", pageAdType)
-	params.Set("ad_active_status", pageStatus)
-	params.Set("ad_reached_countries", toJSONArray(pageCountries))
-	params.Set("search_page_ids", toJSONArray([]string{pageID}))
+	params.Set("fields", api.FieldsSummary)
+	if pageAllFields {
+		params.Set("fields", api.FieldsAll)
+	}
+	params.Set("ad_type", adType)
+	params.Set("ad_active_status", status)
+	params.Set("ad_reached_countries", toJSONArray(countries))
+	params.Set("search_page_ids", toJSONArray(pageIDs))
 
-	if pageDateMin != "" {
-		params.Set("ad_delivery_date_min", pageDateMin)
+	dateMin, dateMax, err := normalizeDateRange(pageDateMin, pageDateMax)
+	if err != nil {
+		return err
 	}
-	if pageDateMax != "" {
-		params.Set("ad_delivery_date_max", pageDateMax)
+	if dateMin != "" {
+		params.Set("ad_delivery_date_min", dateMin)
+	}
+	if dateMax != "" {
+		params.Set("ad_delivery_date_max", dateMax)
+	}
+	if pageUnmask {
+		params.Set("unmask_removed_content", "true")
+	}
+
+	if err := applyRawParams(params, pageRawParams); err != nil {
+		return err
 	}
 
-	items, err := client.SearchAds(params, pageLimit)
+	opts := api.SearchOptions{Limit: pageLimit, FirstPageOnly: pageFirstOnly}
+	if pagePerCountry {
+		return runPageAdsPerCountry(cmd, params, countries, opts)
+	}
+	if pageDryRun {
+		return printDryRun(client.SearchURL(params, opts))
+	}
+
+	noteLimitCost([]typedParams{{adType, params}}, opts)
+	res, err := client.Search(params, opts)
 	if err != nil {
 		return err
 	}
+	items := filterSpendTiers(res.Items)
+	if res.Truncated {
+		warnTruncated(pageLimit, pageFirstOnly)
+	}
+
+	if pageSaveLast {
+		saveLast(cmd, items)
+	}
+	archiveResults(cmd, strings.Join(pageIDs, " "), items)
+	alsoErr := writeAlsoFiles(items)
+	if err := printPageAds(cmd, items, params.Get("fields"), pageIDs); err != nil {
+		return err
+	}
+	return alsoErr
+}
+
+// printPageAds prints the ads of pageIDs in the output format asked for.
+func printPageAds(cmd *cobra.Command, items []json.RawMessage, fields string, pageIDs []string) error {
+	if pageURLsOnly {
+		return printSnapshotURLs(items)
+	}
 
 	if len(items) == 0 {
 		if output.IsJSON(cmd) {
 			fmt.Println("[]")
 			return nil
 		}
-		fmt.Printf("no ads found for page %s\n", pageID)
+		fmt.Printf("no ads found for page(s) %s\n", strings.Join(pageIDs, ", "))
 		return nil
 	}
 
 	if output.IsJSON(cmd) {
-		var raw []json.RawMessage
-		raw = append(raw, items...)
+		raw, err := adsJSON(items)
+		if err != nil {
+			return err
+		}
 		return output.PrintJSON(raw, output.IsPretty(cmd))
 	}
 
-	ads := make([]api.AdArchiveRecord, 0, len(items))
-	for _, raw := range items {
-		var a api.AdArchiveRecord
-		if err := json.Unmarshal(raw, &a); err != nil {
-			return fmt.Errorf("parsing ad: %w", err)
+	ads := parseAds(items)
+	noteUnrequestedColumns(fields)
+
+	if pageByStatus {
+		printAdsByStatus(ads)
+	} else {
+		printAdsTable(ads, nil)
+	}
+	if len(pageIDs) == 1 {
+		fmt.Printf("\n%d ad(s) for page %s\n", len(ads), pageIDs[0])
+	} else {
+		fmt.Printf("\n%d ad(s) across %d pages\n", len(ads), len(pageIDs))
+		printPageCounts(ads, pageIDs)
+	}
+	printAdsSummary(ads)
+	return nil
+}
+
+func runPageSearch(cmd *cobra.Command, args []string) error {
+	if pageSearchLimit < 0 {
+		return usageErrorf("--limit must not be negative")
+	}
+	name := strings.Join(args, " ")
+	pages, err := client.SearchPages(name)
+	if err != nil {
+		return err
+	}
+	if pageSearchLimit > 0 && len(pages) > pageSearchLimit {
+		pages = pages[:pageSearchLimit]
+	}
+
+	if output.IsJSON(cmd) {
+		if pages == nil {
+			pages = []api.Page{}
+		}
+		return output.PrintJSON(pages, output.IsPretty(cmd))
+	}
+	if len(pages) == 0 {
+		fmt.Printf("no pages found matching %q\n", name)
+		return nil
+	}
+	rows := make([][]string, len(pages))
+	for i, p := range pages {
+		rows[i] = []string{p.ID, p.Name, p.Category, verificationLabel(p.VerificationStatus)}
+	}
+	output.PrintTable([]string{"ID", "NAME", "CATEGORY", "VERIFIED"}, rows)
+	fmt.Printf("\n%d page(s) — list their ads with: meta-adlib page ads <id> --country XX\n", len(pages))
+	return nil
+}
+
+// verificationLabel shortens a verification_status for the table.
+func verificationLabel(status string) string {
+	switch status {
+	case "blue_verified":
+		return "yes (blue)"
+	case "gray_verified":
+		return "yes (gray)"
+	case "not_verified":
+		return "no"
+	}
+	return status
+}
+
+// countryTotals is one row of page ads --per-country.
+type countryTotals struct {
+	Country string `json:"country"`
+	Ads     int    `json:"ads"`
+	Active  int    `json:"active"`
+	// Spend is the estimated total per currency, e.g. "100–500 EUR".
+	Spend string `json:"spend_estimate"`
+}
+
+// runPageAdsPerCountry runs the page query once per country and prints the
+// number of ads and the estimated spend reaching each one. An ad that reached
+// several countries is counted in each.
+func runPageAdsPerCountry(cmd *cobra.Command, params url.Values, countries []string, opts api.SearchOptions) error {
+	var totals []countryTotals
+	truncated := false
+	for i, country := range countries {
+		country = strings.ToUpper(country)
+		p := maps.Clone(params)
+		p.Set("ad_reached_countries", toJSONArray([]string{country}))
+		if pageDryRun {
+			if err := printDryRun(client.SearchURL(p, opts)); err != nil {
+				return err
+			}
+			continue
+		}
+
+		res, err := client.Search(p, opts)
+		if err != nil {
+			return fmt.Errorf("%s: %w", country, err)
+		}
+		truncated = truncated || res.Truncated
+		ads := parseAds(filterSpendTiers(res.Items))
+		t := countryTotals{Country: country, Ads: len(ads), Spend: spendTotal(ads)}
+		for _, a := range ads {
+			if adStatus(a) == "active" {
+				t.Active++
+			}
+		}
+		totals = append(totals, t)
+		if res.DeadlineReached {
+			if rest := countries[i+1:]; len(rest) > 0 {
+				slog.Warn(fmt.Sprintf("--deadline reached; skipped %s", strings.ToUpper(strings.Join(rest, ", "))))
+			}
+			break
 		}
-		ads = append(ads, a)
+	}
+	if pageDryRun {
+		return nil
+	}
+	if truncated {
+		warnTruncated(pageLimit, pageFirstOnly)
 	}
 
-	printAdsTable(ads)
-	fmt.Printf("\n%d ad(s) for page %s\n", len(ads), pageID)
+	if output.IsJSON(cmd) {
+		return output.PrintJSON(totals, output.IsPretty(cmd))
+	}
+	rows := make([][]string, len(totals))
+	for i, t := range totals {
+		rows[i] = []string{t.Country, strconv.Itoa(t.Ads), strconv.Itoa(t.Active), t.Spend}
+	}
+	output.PrintTable([]string{"COUNTRY", "ADS", "ACTIVE", "SPEND (EST.)"}, rows)
+	if len(totals) > 1 {
+		fmt.Println("\nads reaching several countries are counted in each")
+	}
 	return nil
 }
+
+// printAdsByStatus prints active ads, then inactive ones, as separate tables
+// with a spend subtotal under each.
+func printAdsByStatus(ads []api.AdArchiveRecord) {
+	var active, inactive []api.AdArchiveRecord
+	for _, a := range ads {
+		if adStatus(a) == "active" {
+			active = append(active, a)
+		} else {
+			inactive = append(inactive, a)
+		}
+	}
+	for i, group := range []struct {
+		title string
+		ads   []api.AdArchiveRecord
+	}{{"ACTIVE", active}, {"INACTIVE", inactive}} {
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Printf("%s (%d)\n", group.title, len(group.ads))
+		if len(group.ads) == 0 {
+			continue
+		}
+		printAdsTable(group.ads, nil)
+		fmt.Printf("subtotal spend (est.): %s\n", spendTotal(group.ads))
+	}
+}
+
+// printPageCounts prints how many of ads belong to each requested page.
+func printPageCounts(ads []api.AdArchiveRecord, pageIDs []string) {
+	counts := map[string]int{}
+	names := map[string]string{}
+	for _, a := range ads {
+		counts[a.PageID]++
+		names[a.PageID] = a.PageName
+	}
+	for _, id := range pageIDs {
+		label := id
+		if names[id] != "" {
+			label = names[id] + " (" + id + ")"
+		}
+		fmt.Printf("  %-40s %d ad(s)\n", label, counts[id])
+	}
+}
@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"sync"
+	"time"
+)
+
+// defaultConcurrency is the default --concurrency for bulk fetches.
+const defaultConcurrency = 4
+
+// concurrencyFlag sizes the worker pool of every multi-fetch operation
+// (ad get with several IDs, ad snapshot --images).
+var concurrencyFlag int
+
+// runPool calls fn(i) for every i in [0, n) using up to workers goroutines,
+// and returns once all calls are done. fn must only write to index i of any
+// shared result slices.
+func runPool(n, workers int, fn func(i int)) {
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for range min(workers, n) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				fn(i)
+			}
+		}()
+	}
+	for i := range n {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+}
+
+// throttle lets the workers of a pool back off together: when one of them
+// hits a rate limit it pauses the whole pool, so the other workers don't keep
+// hammering the quota in the meantime.
+type throttle struct {
+	mu    sync.Mutex
+	until time.Time
+}
+
+// pause holds every worker calling wait for at least d from now.
+func (t *throttle) pause(d time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if until := time.Now().Add(d); until.After(t.until) {
+		t.until = until
+	}
+}
+
+// wait blocks until any pause in effect has elapsed.
+func (t *throttle) wait() {
+	t.mu.Lock()
+	d := time.Until(t.until)
+	t.mu.Unlock()
+	if d > 0 {
+		time.Sleep(d)
+	}
+}
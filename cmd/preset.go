@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/the20100/meta-ad-library-cli/internal/config"
+)
+
+var searchSavePresetCmd = &cobra.Command{
+	Use:   "save-preset <name>",
+	Short: "Save the given search flags under a name for reuse with --preset",
+	Long: `Stores the search flags passed to this command in the config file under
+<name>. Only flags you actually pass are saved; replay them with
+"meta-adlib search --preset <name>", where any flag given on the command line
+overrides the preset. Saving under an existing name replaces it.
+
+Examples:
+  meta-adlib search save-preset climate-us --query climate --country US --status ACTIVE
+  meta-adlib search --preset climate-us
+  meta-adlib search --preset climate-us --country CA --limit 100`,
+	Args: cobra.ExactArgs(1),
+	RunE: runSearchSavePreset,
+}
+
+func init() {
+	addSearchFlags(searchSavePresetCmd.Flags())
+	addSearchQueryFlags(searchSavePresetCmd.Flags())
+
+	searchCmd.AddCommand(searchSavePresetCmd)
+}
+
+func runSearchSavePreset(cmd *cobra.Command, args []string) error {
+	name := args[0]
+
+	flags := map[string][]string{}
+	cmd.Flags().Visit(func(f *pflag.Flag) {
+		if cmd.InheritedFlags().Lookup(f.Name) != nil {
+			return // global flags like --json are not part of the query
+		}
+		if sv, ok := f.Value.(pflag.SliceValue); ok {
+			flags[f.Name] = sv.GetSlice()
+		} else {
+			flags[f.Name] = []string{f.Value.String()}
+		}
+	})
+	if len(flags) == 0 {
+		return usageErrorf("no search flags given — pass the flags to save, e.g. save-preset %s --query climate --country US", name)
+	}
+
+	f, err := config.LoadFile()
+	if err != nil {
+		return fmt.Errorf("failed to load config %s: %w", config.Path(), err)
+	}
+	if f.Presets == nil {
+		f.Presets = map[string]map[string][]string{}
+	}
+	_, replaced := f.Presets[name]
+	f.Presets[name] = flags
+	if err := config.SaveFile(f); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+
+	verb := "saved"
+	if replaced {
+		verb = "replaced"
+	}
+	fmt.Printf("preset %s %s (%s)\n", name, verb, strings.Join(presetFlagNames(flags), ", "))
+	return nil
+}
+
+// applyPreset sets every flag stored in the named preset that was not given
+// explicitly on the command line.
+func applyPreset(cmd *cobra.Command, name string) error {
+	f, err := config.LoadFile()
+	if err != nil {
+		return fmt.Errorf("failed to load config %s: %w", config.Path(), err)
+	}
+	preset, ok := f.Presets[name]
+	if !ok {
+		names := make([]string, 0, len(f.Presets))
+		for n := range f.Presets {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		if len(names) == 0 {
+			return usageErrorf("preset %q not found — save one with: meta-adlib search save-preset %s <flags>", name, name)
+		}
+		return usageErrorf("preset %q not found — available: %s", name, strings.Join(names, ", "))
+	}
+
+	for _, flag := range presetFlagNames(preset) {
+		if cmd.Flags().Changed(flag) {
+			continue
+		}
+		for _, v := range preset[flag] {
+			if err := cmd.Flags().Set(flag, v); err != nil {
+				return usageErrorf("preset %q: invalid --%s %q: %v", name, flag, v, err)
+			}
+		}
+	}
+	return nil
+}
+
+// presetFlagNames returns the flag names stored in a preset, sorted.
+func presetFlagNames(preset map[string][]string) []string {
+	names := make([]string, 0, len(preset))
+	for n := range preset {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,168 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/the20100/meta-ad-library-cli/internal/api"
+)
+
+var (
+	searchQueriesFile string
+	searchDedupe      bool
+)
+
+// queryRun is one search of a --queries-file sweep.
+type queryRun struct {
+	query  string
+	params url.Values
+}
+
+// readQueriesFile returns the search terms in path, one per line. Blank lines
+// and lines starting with "#" are skipped, as are repeats.
+func readQueriesFile(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, usageErrorf("--queries-file: %v", err)
+	}
+	defer f.Close()
+
+	var queries []string
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		q := strings.TrimSpace(sc.Text())
+		if q == "" || strings.HasPrefix(q, "#") || slices.Contains(queries, q) {
+			continue
+		}
+		queries = append(queries, q)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+	if len(queries) == 0 {
+		return nil, usageErrorf("--queries-file %s has no queries", path)
+	}
+	return queries, nil
+}
+
+// queryTags maps each ad ID a --queries-file sweep returned to the queries
+// that found it, in order: one per copy of the ad in the results.
+type queryTags map[string][]string
+
+// adQueries are the tags of the ads being printed; nil without
+// --queries-file. They show as the QUERY column and the source_query key.
+var adQueries queryTags
+
+// tagger returns a function that, called with the ID of each ad in turn,
+// returns the query that found it: the nth copy of an ad gets the nth
+// query. Copies of an ad are identical, so which copy gets which query
+// doesn't matter once results are filtered or sorted.
+func (t queryTags) tagger() func(id string) string {
+	seen := map[string]int{}
+	return func(id string) string {
+		qs := t[id]
+		if len(qs) == 0 {
+			return ""
+		}
+		n := min(seen[id], len(qs)-1)
+		seen[id]++
+		return qs[n]
+	}
+}
+
+// searchQueries runs the search once per query, up to limit ads each, and
+// concatenates the results in query order, recording in queries which query
+// found every ad. With --dedupe, an ad found by several queries is kept once,
+// under the first. When --deadline stops the sweep, the ads found so far are
+// returned and the remaining queries skipped.
+func (f searchFilters) searchQueries(runs []queryRun, limit int) (*searchResult, error) {
+	out := &searchResult{queries: queryTags{}}
+	seen := map[string]bool{}
+	for i, run := range runs {
+		res, err := f.searchAds(run.params, limit)
+		if err != nil && i > 0 && errors.Is(err, context.DeadlineExceeded) {
+			// The deadline passed between two queries.
+			out.deadlineReached = true
+			warnSkippedQueries(runs[i:])
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("query %q: %w", run.query, err)
+		}
+		out.truncated = out.truncated || res.truncated
+		out.maxPagesReached = out.maxPagesReached || res.maxPagesReached
+		if res.sources != nil {
+			if out.sources == nil {
+				out.sources = map[string]string{}
+			}
+			for id, t := range res.sources {
+				if _, ok := out.sources[id]; !ok {
+					out.sources[id] = t
+				}
+			}
+		}
+		for _, item := range res.items {
+			var ad struct {
+				ID string `json:"id"`
+			}
+			json.Unmarshal(item, &ad) //nolint:errcheck
+			if searchDedupe && ad.ID != "" {
+				if seen[ad.ID] {
+					continue
+				}
+				seen[ad.ID] = true
+			}
+			if ad.ID != "" {
+				out.queries[ad.ID] = append(out.queries[ad.ID], run.query)
+			}
+			out.items = append(out.items, item)
+		}
+		if res.deadlineReached {
+			out.deadlineReached = true
+			warnSkippedQueries(runs[i+1:])
+			break
+		}
+	}
+	return out, nil
+}
+
+// warnSkippedQueries reports the queries a --deadline left unsearched.
+func warnSkippedQueries(rest []queryRun) {
+	if len(rest) == 0 {
+		return
+	}
+	names := make([]string, len(rest))
+	for i, run := range rest {
+		names[i] = strconv.Quote(run.query)
+	}
+	slog.Warn(fmt.Sprintf("--deadline reached; skipped %s", strings.Join(names, ", ")))
+}
+
+// printQueryCounts prints how many of ads each query found.
+func printQueryCounts(ads []api.AdArchiveRecord, runs []queryRun) {
+	counts := map[string]int{}
+	queryOf := adQueries.tagger()
+	for _, a := range ads {
+		counts[queryOf(a.ID)]++
+	}
+	for _, run := range runs {
+		fmt.Printf("  %-40s %d ad(s)\n", run.query, counts[run.query])
+	}
+}
+
+// queriesFileName is the --queries-file name without directory or extension,
+// used to name --out-dir files.
+func queriesFileName() string {
+	base := filepath.Base(searchQueriesFile)
+	return strings.TrimSuffix(base, filepath.Ext(base))
+}
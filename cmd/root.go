@@ -1,29 +1,65 @@
 package cmd
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"log/slog"
 	"os"
-	"path/filepath"
 	"runtime"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/the20100/meta-ad-library-cli/internal/api"
 	"github.com/the20100/meta-ad-library-cli/internal/config"
+	"github.com/the20100/meta-ad-library-cli/internal/logging"
 	"github.com/the20100/meta-ad-library-cli/internal/metaauth"
+	"github.com/the20100/meta-ad-library-cli/internal/output"
 )
 
 var (
-	jsonFlag   bool
-	prettyFlag bool
+	jsonFlag    bool
+	prettyFlag  bool
+	tokenFlag   string
+	profileFlag string
+	noColorFlag bool
+	localeFlag  string
+	configFlag  string
+
+	rateWarnAtFlag int
+	noRateWarnFlag bool
+	retriesFlag    int
+	retryDelayFlag time.Duration
+	deadlineFlag   time.Duration
+	canonicalFlag  bool
+	envFileFlag    string
+	logLevelFlag   string
+	quietFlag      bool
+
+	infoShowToken bool
+
+	// preRunReached is set once flags and args have been validated; see exitCode.
+	preRunReached bool
 
 	// Global API client, initialized in PersistentPreRunE.
 	client *api.Client
-	cfg    *config.Config
+	// cancelDeadline releases the --deadline timer.
+	cancelDeadline context.CancelFunc = func() {}
+	cfg            *config.Config
 )
 
+// version is the release version, set at build time with
+// -ldflags "-X github.com/the20100/meta-ad-library-cli/cmd.version=v1.2.3".
+var version = "dev"
+
+// userAgent identifies this CLI on outbound Graph API requests.
+func userAgent() string {
+	return "meta-adlib/" + version + " (+github.com/the20100/meta-ad-library-cli)"
+}
+
 var rootCmd = &cobra.Command{
 	Use:   "meta-adlib",
 	Short: "Meta Ad Library CLI — search and explore public Meta ads",
@@ -38,6 +74,7 @@ Available for:
   • Ads in Brazil (limited scope)
 
 Token resolution order:
+  0. --token flag  (one-off override, never touches config)
   1. META_TOKEN env var
   2. Own config    (~/.config/meta-ad-library/config.json  via: meta-adlib auth set-token)
   3. Shared config (~/.config/meta-auth/config.json        via: meta-auth login)
@@ -48,21 +85,150 @@ Examples:
   meta-adlib search --query "election" --country US --type POLITICAL_AND_ISSUE_ADS
   meta-adlib search --page-id 123456789 --country DE
   meta-adlib ad get <ad_archive_id>`,
-	SilenceUsage: true,
+	Version:       version,
+	SilenceUsage:  true,
+	SilenceErrors: true,
 }
 
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
-		os.Exit(1)
+	cmd, err := rootCmd.ExecuteC()
+	cancelDeadline()
+	reportUsage()
+	if err != nil {
+		if cmd.Flags().Changed("json") || cmd.Flags().Changed("pretty") {
+			printJSONError(err)
+		} else {
+			output.PrintError(err)
+		}
+		os.Exit(exitCode(err, preRunReached))
+	}
+}
+
+// reportUsage notes on stderr how many API calls the command made and the
+// last app usage Meta reported, so each run shows its share of the quota.
+// Commands that made no calls print nothing.
+func reportUsage() {
+	if client == nil {
+		return
+	}
+	u := client.Usage()
+	if u.Calls == 0 {
+		return
 	}
+	msg := fmt.Sprintf("made %d API call(s)", u.Calls)
+	if u.HasAppUsage {
+		msg += fmt.Sprintf("; app usage %d%%", u.AppUsage)
+	}
+	slog.Info(msg)
+}
+
+// jsonError is the error envelope printed in JSON mode. Code, Type and
+// Subcode are filled in when the failure came from the Graph API; Hint, when
+// there is a known next step.
+type jsonError struct {
+	Error struct {
+		Message string `json:"message"`
+		Code    int    `json:"code,omitempty"`
+		Type    string `json:"type,omitempty"`
+		Subcode int    `json:"error_subcode,omitempty"`
+		Hint    string `json:"hint,omitempty"`
+	} `json:"error"`
+}
+
+// printJSONError writes err to stderr as {"error": {...}} so scripts asking
+// for --json can parse failures too, without the error ending up in the
+// output they redirect.
+func printJSONError(err error) {
+	var e jsonError
+	e.Error.Message = err.Error()
+	var metaErr *api.MetaError
+	if errors.As(err, &metaErr) {
+		e.Error.Message = metaErr.Message
+		e.Error.Code = metaErr.Code
+		e.Error.Type = metaErr.Type
+		e.Error.Subcode = metaErr.Subcode
+		if metaErr.IsAdLibraryAccess() {
+			e.Error.Hint = api.AdLibraryAccessHint
+		}
+	}
+	json.NewEncoder(output.Err).Encode(e) //nolint:errcheck
 }
 
 func init() {
+	// Until --log-level is parsed in PersistentPreRunE.
+	logging.Setup(output.Err, slog.LevelInfo)
+
 	rootCmd.PersistentFlags().BoolVar(&jsonFlag, "json", false, "Force JSON output")
 	rootCmd.PersistentFlags().BoolVar(&prettyFlag, "pretty", false, "Force pretty-printed JSON output (implies --json)")
+	rootCmd.PersistentFlags().StringVar(&tokenFlag, "token", "", "Access token to use for this invocation (overrides env and config)")
+	rootCmd.PersistentFlags().StringVar(&profileFlag, "profile", "", "Config profile to use for this invocation (default: the active profile)")
+	rootCmd.PersistentFlags().IntVar(&rateWarnAtFlag, "rate-warn-at", api.DefaultRateWarnAt, "Warn when API usage exceeds this percentage (also: META_ADLIB_RATE_WARN_AT)")
+	rootCmd.PersistentFlags().BoolVar(&noRateWarnFlag, "no-rate-warn", false, "Suppress rate-limit usage warnings (other warnings still print)")
+	rootCmd.PersistentFlags().StringVar(&configFlag, "config", "", "Config file path (overrides META_ADLIB_CONFIG and the OS default)")
+	rootCmd.PersistentFlags().StringVar(&envFileFlag, "env-file", "", "Load environment variables from this file (default: ./.env if present; real env vars win)")
+	rootCmd.PersistentFlags().StringSliceVar(&output.Select, "select", nil, "Keep only these top-level keys in each JSON object of the output (e.g. id,spend)")
+	rootCmd.PersistentFlags().BoolVar(&noValidateFieldsFlag, "no-validate-fields", false, "Send --fields as given, without checking names against the known field list")
+	rootCmd.PersistentFlags().StringVar(&logLevelFlag, "log-level", "info", "Minimum level of diagnostics on stderr: debug (adds request tracing), info, warn, error")
+	rootCmd.PersistentFlags().BoolVarP(&quietFlag, "quiet", "q", false, "Print only warnings and errors on stderr, without notes such as the API usage summary (same as --log-level warn)")
+	rootCmd.PersistentFlags().IntVar(&retriesFlag, "retries", 0, "Retry failed API requests this many times (network errors, HTTP 5xx, rate limits, transient Meta errors)")
+	rootCmd.PersistentFlags().DurationVar(&retryDelayFlag, "retry-delay", 2*time.Second, "Wait before the first retry; doubles on each retry, up to 1m")
+	rootCmd.PersistentFlags().DurationVar(&deadlineFlag, "deadline", 0, "Stop all API calls after this long (e.g. 2m), keeping the results fetched so far (0 = no limit)")
+	rootCmd.PersistentFlags().IntVar(&concurrencyFlag, "concurrency", defaultConcurrency, "Number of parallel requests for bulk fetches (1 = one at a time)")
+	rootCmd.PersistentFlags().IntVar(&output.Width, "width", 0, "Maximum table width in columns; wider tables are truncated to fit (default: terminal width)")
+	rootCmd.PersistentFlags().BoolVar(&canonicalFlag, "canonical", false, "Write ads in JSON output with a fixed key order, so identical results give byte-identical files")
+	rootCmd.PersistentFlags().StringVar(&localeFlag, "locale", "", "Format numbers and dates in tables and detail views per this locale (e.g. de-DE, fr-FR; default: 1234 and 2006-01-02)")
+	rootCmd.PersistentFlags().BoolVar(&noColorFlag, "no-color", false, "Disable terminal escape sequences such as clickable links (also: NO_COLOR env)")
+	infoCmd.Flags().BoolVar(&infoShowToken, "show-token", false, "Print the resolved token in full instead of masked")
 	rootCmd.AddCommand(infoCmd)
 	rootCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
-		if isAuthCommand(cmd) {
+		preRunReached = true
+		// Cobra checks these only after this hook; check them first so they
+		// are reported as usage errors.
+		if err := cmd.ValidateRequiredFlags(); err != nil {
+			return usageError{err}
+		}
+		if err := cmd.ValidateFlagGroups(); err != nil {
+			return usageError{err}
+		}
+		if quietFlag {
+			if cmd.Flags().Changed("log-level") {
+				return usageErrorf("--quiet and --log-level cannot be used together")
+			}
+			logLevelFlag = "warn"
+		}
+		level, err := logging.ParseLevel(logLevelFlag)
+		if err != nil {
+			return usageErrorf("invalid --log-level: %v", err)
+		}
+		logging.Setup(output.Err, level)
+
+		envFile := envFileFlag
+		if envFile == "" {
+			envFile = defaultEnvFile
+		}
+		if err := loadEnvFile(envFile, envFileFlag != ""); err != nil {
+			return err
+		}
+		config.SetPath(configFlag)
+		config.SetProfile(profileFlag)
+		output.NoColor = noColorFlag
+		if concurrencyFlag < 1 {
+			return usageErrorf("--concurrency must be at least 1")
+		}
+		if !output.SetLocale(localeFlag) {
+			return usageErrorf("--locale %q is not supported (supported: %s)", localeFlag, strings.Join(output.Locales(), ", "))
+		}
+		if output.Width < 0 {
+			return usageErrorf("--width must not be negative")
+		}
+		if retriesFlag < 0 {
+			return usageErrorf("--retries must not be negative")
+		}
+		if deadlineFlag < 0 {
+			return usageErrorf("--deadline must not be negative")
+		}
+
+		if isTokenless(cmd) {
 			return nil
 		}
 
@@ -71,11 +237,49 @@ func init() {
 			return err
 		}
 
-		client = api.NewClient(token)
+		rateWarnAt, err := resolveRateWarnAt(cmd)
+		if err != nil {
+			return err
+		}
+
+		ctx := context.Background()
+		if deadlineFlag > 0 {
+			ctx, cancelDeadline = context.WithTimeout(ctx, deadlineFlag)
+		}
+		opts := []api.Option{api.WithRateWarnAt(rateWarnAt), api.WithRateWarnings(!noRateWarnFlag),
+			api.WithUserAgent(userAgent()), api.WithContext(ctx), api.WithRetry(api.RetryConfig{
+				MaxAttempts: retriesFlag + 1,
+				BaseDelay:   retryDelayFlag,
+				MaxDelay:    maxRetryDelay,
+			})}
+		if cmd == serveCmd {
+			opts = append(opts, api.WithObserver(serveStats))
+		}
+		client = api.NewClient(token, opts...)
 		return nil
 	}
 }
 
+// maxRetryDelay caps the --retry-delay backoff.
+const maxRetryDelay = time.Minute
+
+// resolveRateWarnAt returns --rate-warn-at if given, else META_ADLIB_RATE_WARN_AT,
+// else the default.
+func resolveRateWarnAt(cmd *cobra.Command) (int, error) {
+	pct := rateWarnAtFlag
+	if v := os.Getenv("META_ADLIB_RATE_WARN_AT"); v != "" && !cmd.Flags().Changed("rate-warn-at") {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return 0, usageErrorf("invalid META_ADLIB_RATE_WARN_AT %q — expected a percentage", v)
+		}
+		pct = n
+	}
+	if pct < 0 || pct > 100 {
+		return 0, usageErrorf("--rate-warn-at must be between 0 and 100, got %d", pct)
+	}
+	return pct, nil
+}
+
 var infoCmd = &cobra.Command{
 	Use:   "info",
 	Short: "Show tool info: config paths, token status, and environment",
@@ -85,9 +289,8 @@ var infoCmd = &cobra.Command{
 }
 
 func printInfo() {
-	configDir, _ := os.UserConfigDir()
-	ownConfig := filepath.Join(configDir, "meta-ad-library", "config.json")
-	sharedConfig := filepath.Join(configDir, "meta-auth", "config.json")
+	ownConfig := config.Path()
+	sharedConfig, _ := metaauth.Path()
 
 	fmt.Println("meta-adlib — Meta Ad Library CLI")
 	fmt.Println()
@@ -101,33 +304,55 @@ func printInfo() {
 	fmt.Println("    macOS:    ~/Library/Application Support/meta-ad-library/config.json")
 	fmt.Println("    Linux:    ~/.config/meta-ad-library/config.json")
 	fmt.Println("    Windows:  %AppData%\\meta-ad-library\\config.json")
-	fmt.Printf("  own config:    %s\n", ownConfig)
+	fmt.Println("    any OS:   $XDG_CONFIG_HOME/meta-ad-library/config.json (when set)")
+	fmt.Printf("  own config:    %s (effective)\n", ownConfig)
 	fmt.Printf("  shared config: %s\n", sharedConfig)
+	fmt.Printf("  profile:       %s\n", activeProfile())
 	fmt.Println()
 
 	// Token source
 	tokenSource := "(not set)"
 	userName := ""
-	if t := os.Getenv("META_TOKEN"); t != "" {
+	var scopes []string
+	ownToken := false
+	if tokenFlag != "" {
+		tokenSource = "--token flag"
+	} else if t := os.Getenv("META_TOKEN"); t != "" {
 		tokenSource = "META_TOKEN env var"
-	} else if tok, name := readTokenFromFile(ownConfig); tok != "" {
-		tokenSource = "own config"
-		userName = name
+	} else if c, err := config.Load(); err == nil && c.AccessToken != "" {
+		tokenSource = "own config (profile: " + activeProfile() + ")"
+		userName = c.UserName
+		scopes = c.Scopes
+		ownToken = true
 	} else if tok, name := readTokenFromFile(sharedConfig); tok != "" {
 		tokenSource = "meta-auth shared config"
 		userName = name
 	}
 	fmt.Printf("  token source: %s\n", tokenSource)
+	if client != nil {
+		if infoShowToken {
+			fmt.Printf("  token:        %s\n", client.Token())
+		} else {
+			fmt.Printf("  token:        %s  (reveal with --show-token)\n", maskOrEmpty(client.Token()))
+		}
+	}
 	if userName != "" {
 		fmt.Printf("  user:         %s\n", userName)
 	}
-	printExpiryFromFile(ownConfig, sharedConfig)
+	if ownToken {
+		printScopes("  scopes:       ", scopes)
+	}
+	printExpiry(sharedConfig)
+	warnClockSkew()
 
 	fmt.Println()
 	fmt.Println("  env vars:")
 	fmt.Printf("    META_TOKEN = %s\n", maskOrEmpty(os.Getenv("META_TOKEN")))
+	fmt.Printf("    META_ADLIB_CONFIG = %s\n", orNotSet(os.Getenv("META_ADLIB_CONFIG")))
+	fmt.Printf("    XDG_CONFIG_HOME = %s\n", orNotSet(os.Getenv("XDG_CONFIG_HOME")))
 	fmt.Println()
 	fmt.Println("  token resolution order:")
+	fmt.Println("    0. --token flag (one-off override)")
 	fmt.Println("    1. META_TOKEN env var")
 	fmt.Println("    2. own config   (meta-adlib auth set-token)")
 	fmt.Println("    3. shared config (meta-auth login)  ← recommended")
@@ -148,17 +373,22 @@ func readTokenFromFile(path string) (token, userName string) {
 	return "", ""
 }
 
-func printExpiryFromFile(ownPath, sharedPath string) {
-	for _, path := range []string{ownPath, sharedPath} {
-		data, err := os.ReadFile(path)
-		if err != nil {
-			continue
-		}
-		var cfg struct {
-			AccessToken    string `json:"access_token"`
-			TokenExpiresAt int64  `json:"token_expires_at"`
+// printExpiry prints the expiry of the first configured token: the active own
+// profile, then the shared meta-auth config.
+func printExpiry(sharedPath string) {
+	var candidates []config.Config
+	if c, err := config.Load(); err == nil {
+		candidates = append(candidates, *c)
+	}
+	if data, err := os.ReadFile(sharedPath); err == nil {
+		var shared config.Config
+		if json.Unmarshal(data, &shared) == nil {
+			candidates = append(candidates, shared)
 		}
-		if json.Unmarshal(data, &cfg) != nil || cfg.AccessToken == "" {
+	}
+
+	for _, cfg := range candidates {
+		if cfg.AccessToken == "" {
 			continue
 		}
 		if cfg.TokenExpiresAt == 0 {
@@ -176,6 +406,25 @@ func printExpiryFromFile(ownPath, sharedPath string) {
 	}
 }
 
+// activeProfile returns the name of the profile in effect for this invocation.
+func activeProfile() string {
+	f, err := config.LoadFile()
+	if err != nil {
+		if profileFlag != "" {
+			return profileFlag
+		}
+		return config.DefaultProfile
+	}
+	return f.Active()
+}
+
+func orNotSet(v string) string {
+	if v == "" {
+		return "(not set)"
+	}
+	return v
+}
+
 func maskOrEmpty(v string) string {
 	if v == "" {
 		return "(not set)"
@@ -198,6 +447,11 @@ func resolveEnv(names ...string) string {
 
 // resolveToken returns the best available token using the priority chain.
 func resolveToken() (string, error) {
+	// 0. --token flag: explicit one-off override, no config I/O and no expiry warnings
+	if tokenFlag != "" {
+		return tokenFlag, nil
+	}
+
 	// 1. META_TOKEN env var (universal override for all Meta CLIs; try all aliases)
 	if t := resolveEnv(
 		"META_TOKEN", "META_ACCESS_TOKEN", "META_API_TOKEN", "META_BEARER_TOKEN",
@@ -227,7 +481,7 @@ func resolveToken() (string, error) {
 		return sharedToken, nil
 	}
 
-	return "", fmt.Errorf("not authenticated — run: meta-auth login  (shared)\nor: meta-adlib auth set-token <token>  (local only)")
+	return "", authErrorf("not authenticated — run: meta-auth login  (shared)\nor: meta-adlib auth set-token <token>  (local only)")
 }
 
 func warnOwnExpiry() {
@@ -237,9 +491,9 @@ func warnOwnExpiry() {
 	days := cfg.DaysUntilExpiry()
 	switch {
 	case cfg.IsExpired():
-		fmt.Fprintf(os.Stderr, "warning: token has expired — run: meta-adlib auth refresh\n")
+		slog.Warn("token has expired — run: meta-adlib auth refresh")
 	case days >= 0 && days <= 7:
-		fmt.Fprintf(os.Stderr, "warning: token expires in %d day(s) — run: meta-adlib auth refresh\n", days)
+		slog.Warn(fmt.Sprintf("token expires in %d day(s) — run: meta-adlib auth refresh", days))
 	}
 }
 
@@ -247,22 +501,29 @@ func warnSharedExpiry() {
 	days := metaauth.DaysUntilExpiry()
 	switch {
 	case metaauth.IsExpired():
-		fmt.Fprintf(os.Stderr, "warning: meta-auth token has expired — run: meta-auth refresh\n")
+		slog.Warn("meta-auth token has expired — run: meta-auth refresh")
 	case days >= 0 && days <= 7:
-		fmt.Fprintf(os.Stderr, "warning: meta-auth token expires in %d day(s) — run: meta-auth refresh\n", days)
+		slog.Warn(fmt.Sprintf("meta-auth token expires in %d day(s) — run: meta-auth refresh", days))
 	}
 }
 
-func isAuthCommand(cmd *cobra.Command) bool {
-	if cmd.Name() == "auth" {
+// tokenlessCommands are command groups that manage local state and run
+// without resolving a token.
+var tokenlessCommands = map[string]bool{"auth": true, "config": true, "save-preset": true, "last": true, "cache": true}
+
+func isTokenless(cmd *cobra.Command) bool {
+	// Commands reading saved ads from --input don't call the API.
+	if f := cmd.Flags().Lookup("input"); f != nil && f.Changed {
+		return true
+	}
+	// ad open --permalink builds the public URL from the ID alone.
+	if cmd == adOpenCmd && adOpenPermalink {
 		return true
 	}
-	p := cmd.Parent()
-	for p != nil {
-		if p.Name() == "auth" {
+	for c := cmd; c != nil; c = c.Parent() {
+		if tokenlessCommands[c.Name()] {
 			return true
 		}
-		p = p.Parent()
 	}
 	return false
 }
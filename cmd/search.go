@@ -2,35 +2,76 @@ package cmd
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log/slog"
+	"math/rand/v2"
 	"net/url"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 	"github.com/the20100/meta-ad-library-cli/internal/api"
+	"github.com/the20100/meta-ad-library-cli/internal/config"
 	"github.com/the20100/meta-ad-library-cli/internal/output"
 )
 
-// All available fields for /ads_archive (funding_entity deprecated since v13)
-const defaultFields = "id,ad_creation_time,ad_delivery_start_time,ad_delivery_stop_time," +
-	"ad_creative_bodies,ad_creative_link_titles,ad_creative_link_captions," +
-	"ad_snapshot_url,page_id,page_name,publisher_platforms,languages," +
-	"spend,impressions,currency"
+// searchFilters holds the /ads_archive filters shared by every command that
+// runs a search.
+type searchFilters struct {
+	Query     string
+	Countries []string
+	PageIDs   []string
+	AdTypes   []string
+	Status    string
+	DateMin   string
+	DateMax   string
+	Platforms []string
+	Languages []string
+	MediaType string
+	HasImage  bool
+	HasVideo  bool
+	// StrictCountry drops ads whose per-country reach excludes Countries.
+	StrictCountry bool
+	// ActiveOnly and IncludeInactive are shorthands for Status ACTIVE and ALL.
+	ActiveOnly      bool
+	IncludeInactive bool
+	// Impression bounds compare against the lower bound of the estimate
+	// (0 = unbounded). Ads without impressions data are dropped when a bound
+	// is set unless IncludeNoImpressions.
+	MinImpressions       int64
+	MaxImpressions       int64
+	IncludeNoImpressions bool
+	// FirstPageOnly fetches a single page per ad type (search --first-page-only).
+	FirstPageOnly bool
+	// UnmaskRemoved asks for the content of ads removed for violating
+	// standards, which Meta only returns to authorized researchers.
+	UnmaskRemoved bool
+	// MaxPages caps the requests per ad type (0 = no cap).
+	MaxPages int
+	// Sample, when positive, returns a random sample of that many ads drawn
+	// from everything fetched; rng is its randomness source.
+	Sample int
+	rng    *rand.Rand
+}
 
 var (
-	searchQuery      string
-	searchCountries  []string
-	searchPageIDs    []string
-	searchAdType     string
-	searchStatus     string
-	searchDateMin    string
-	searchDateMax    string
-	searchPlatforms  []string
-	searchLanguages  []string
-	searchLimit      int
-	searchFields     string
-	searchMediaType  string
+	searchOpts      searchFilters
+	searchLimit     int
+	searchFields    string
+	searchAllFields bool
+	searchPageNames []string
+	searchDryRun    bool
+	searchRawParams []string
+	searchPreset    string
+	searchURLsOnly  bool
+	searchSort      string
+	searchSaveLast  bool
+	searchSeed      uint64
+	searchCount     bool
 )
 
 var searchCmd = &cobra.Command{
@@ -38,16 +79,39 @@ var searchCmd = &cobra.Command{
 	Short: "Search the Meta Ad Library",
 	Long: `Search the Meta Ad Library via the /ads_archive endpoint.
 
-At least one of --query or --page-id is required.
-At least one --country is required.
+At least one of --query, --page-id, or --page-name is required. --page-name
+looks the page up by name first; if several pages match, the candidates are
+listed so you can pick one with --page-id.
+At least one --country is required, unless a default is saved with
+"meta-adlib config set default-country <code>".
+
+Note that --country matches ads that reached the country, including ads
+(often political ones) aimed at several markets that only incidentally
+reached it. --strict-country keeps only ads whose per-country reach breakdown
+includes a requested country; ads without breakdown data (it is mostly
+published for EU ads) are kept.
 
 Ad types:
   ALL                      All ads (default)
   POLITICAL_AND_ISSUE_ADS  Political/issue ads (required for some regions)
 
 Status values:
-  ALL     Active and inactive ads (default)
-  ACTIVE  Currently running ads only
+  ALL       Active and inactive ads (default)
+  ACTIVE    Currently running ads only
+  INACTIVE  Stopped ads only
+
+--active-only is short for --status ACTIVE and --include-inactive for
+--status ALL. --type and --status are case-insensitive.
+
+Table columns (--columns, comma-separated; default id,page,started,status,
+spend,platforms,body):
+  id, page, page_id, started, stopped, status, spend, impressions,
+  spend_per_impression, platforms, languages, body, permalink, spend_tier,
+  query
+spend_per_impression and permalink are derived: the spend midpoint divided
+by the impressions midpoint ("-" when either is missing), and the ad's
+public Ad Library URL. Naming one in --columns also adds it as a key to
+each JSON object.
 
 Platforms:
   facebook, instagram, audience_network, messenger, threads
@@ -55,78 +119,242 @@ Platforms:
 Examples:
   meta-adlib search --query "climate" --country US
   meta-adlib search --query "election" --country US --type POLITICAL_AND_ISSUE_ADS --status ACTIVE
+  meta-adlib search --query "election" --country BR --type ALL --type POLITICAL_AND_ISSUE_ADS
   meta-adlib search --page-id 123456789 --country DE --limit 50
+  meta-adlib search --page-name "Acme Corp" --country US
   meta-adlib search --query "cars" --country FR --country DE --platform facebook --platform instagram
   meta-adlib search --query "health" --country US --since 2024-01-01 --until 2024-12-31
-  meta-adlib search --query "shoes" --country US --json`,
+  meta-adlib search --query "shoes" --country US --json
+  meta-adlib search --query "shoes" --country US --dry-run
+  meta-adlib search --query "shoes" --country US --unmask-removed
+  meta-adlib search --preset climate-us --status ACTIVE
+  meta-adlib search --query "shoes" --country US --sample 50 --max-pages 20 --seed 7
+  meta-adlib search --query "shoes" --country US --status ACTIVE --count
+  meta-adlib search --queries-file keywords.txt --country US --limit 100 --dedupe
+  meta-adlib search --query "shoes" --country US --snapshot-urls --with-token | xargs -n1 open`,
 	RunE: runSearch,
 }
 
 func init() {
-	searchCmd.Flags().StringVar(&searchQuery, "query", "", "Search terms to find in ad creative text")
-	searchCmd.Flags().StringArrayVar(&searchCountries, "country", nil, "Country code(s) (ISO 3166, e.g. US, DE, FR). Repeatable.")
-	searchCmd.Flags().StringArrayVar(&searchPageIDs, "page-id", nil, "Facebook Page ID(s) to search. Repeatable.")
-	searchCmd.Flags().StringVar(&searchAdType, "type", "ALL", "Ad type: ALL or POLITICAL_AND_ISSUE_ADS")
-	searchCmd.Flags().StringVar(&searchStatus, "status", "ALL", "Ad active status: ALL or ACTIVE")
-	searchCmd.Flags().StringVar(&searchDateMin, "since", "", "Minimum delivery start date (YYYY-MM-DD)")
-	searchCmd.Flags().StringVar(&searchDateMax, "until", "", "Maximum delivery start date (YYYY-MM-DD)")
-	searchCmd.Flags().StringArrayVar(&searchPlatforms, "platform", nil, "Platform filter: facebook, instagram, audience_network, messenger, threads. Repeatable.")
-	searchCmd.Flags().StringArrayVar(&searchLanguages, "language", nil, "Language filter (ISO 639-1, e.g. en, fr). Repeatable.")
-	searchCmd.Flags().IntVar(&searchLimit, "limit", 25, "Maximum number of results (0 = fetch all pages)")
-	searchCmd.Flags().StringVar(&searchFields, "fields", defaultFields, "Comma-separated list of fields to return")
-	searchCmd.Flags().StringVar(&searchMediaType, "media-type", "", "Filter by media type: ALL, IMAGE, MEME, VIDEO, NONE")
+	addSearchFlags(searchCmd.Flags())
+	addSearchQueryFlags(searchCmd.Flags())
+	addFXFlags(searchCmd.Flags())
+	searchCmd.MarkFlagsMutuallyExclusive("fields", "all-fields")
+	searchCmd.Flags().BoolVar(&searchDryRun, "dry-run", false, "Print the request URL (token redacted) without calling the API")
+	searchCmd.MarkFlagsMutuallyExclusive("page-name", "dry-run")
+	searchCmd.Flags().StringVar(&searchPreset, "preset", "", "Load flags saved with search save-preset (explicit flags win)")
+	searchCmd.Flags().StringSliceVar(&columnsFlag, "columns", nil, "Comma-separated table columns (see --help); derived ones like spend_per_impression are also added to JSON")
+	searchCmd.Flags().BoolVar(&searchOpts.FirstPageOnly, "first-page-only", false, "Make exactly one request: a single page of up to 2000 ads (or an explicit --limit), never following paging cursors")
+	searchCmd.Flags().IntVar(&searchOpts.MaxPages, "max-pages", 0, "Stop paging after this many requests per ad type (0 = no cap)")
+	searchCmd.Flags().IntVar(&searchOpts.Sample, "sample", 0, "Return a random sample of N ads drawn from all pages fetched (requires --max-pages) instead of the first N")
+	searchCmd.Flags().Uint64Var(&searchSeed, "seed", 0, "Random seed for --sample, for a reproducible sample (default: random)")
+	searchCmd.MarkFlagsMutuallyExclusive("sample", "limit")
+	searchCmd.MarkFlagsMutuallyExclusive("sample", "first-page-only")
+	searchCmd.Flags().BoolVar(&searchCount, "count", false, "Print only the number of matching ads (one request when Meta reports a total, else pages through IDs)")
+	searchCmd.MarkFlagsMutuallyExclusive("count", "sample")
+	searchCmd.Flags().BoolVar(&searchSaveLast, "save-last", false, "Save the results for re-display with the last command")
+	searchCmd.Flags().BoolVar(&searchURLsOnly, "snapshot-urls", false, "Print only each ad's snapshot URL, one per line")
+	searchCmd.MarkFlagsMutuallyExclusive("count", "snapshot-urls")
+	addWithTokenFlag(searchCmd.Flags())
+	addOutDirFlag(searchCmd.Flags())
+	addAlsoFlags(searchCmd.Flags())
+	searchCmd.MarkFlagsMutuallyExclusive("count", "out-dir")
+	searchCmd.MarkFlagsMutuallyExclusive("count", "also-json")
+	searchCmd.MarkFlagsMutuallyExclusive("count", "also-csv")
+	addSpendTierFlags(searchCmd.Flags())
+	searchCmd.MarkFlagsMutuallyExclusive("count", "spend-tier")
+	searchCmd.Flags().StringVar(&searchQueriesFile, "queries-file", "", "Run the search once per line of this file (blank and # lines skipped), --limit ads each, tagging ads with source_query")
+	searchCmd.Flags().BoolVar(&searchDedupe, "dedupe", false, "With --queries-file, keep an ad found by several queries once, under the first")
+	searchCmd.MarkFlagsMutuallyExclusive("queries-file", "query")
+	searchCmd.MarkFlagsMutuallyExclusive("queries-file", "count")
 
 	rootCmd.AddCommand(searchCmd)
 }
 
+// addSearchQueryFlags registers the search command's own flags that describe
+// the query (and so are worth saving in a preset), beyond the shared filters.
+func addSearchQueryFlags(fs *pflag.FlagSet) {
+	fs.IntVar(&searchLimit, "limit", 25, "Maximum number of results (0 = fetch all pages)")
+	fs.StringVar(&searchFields, "fields", api.FieldsSummary, "Comma-separated list of fields to return")
+	fs.BoolVar(&searchAllFields, "all-fields", false, "Request every documented /ads_archive field")
+	fs.StringArrayVar(&searchPageNames, "page-name", nil, "Facebook Page name(s) to resolve to page IDs. Repeatable.")
+	fs.StringArrayVar(&searchRawParams, "param", nil, "Raw Graph API query parameter as key=value, overriding built-in ones. Repeatable.")
+	fs.StringVar(&convertToFlag, "convert-to", "", "Convert spend to this currency (e.g. USD) with approximate exchange rates (see --fx-source), for display, totals and --sort")
+	fs.StringVar(&searchSort, "sort", "", "Sort by estimated spend or impressions (lower bound): spend, -spend, impressions, -impressions (- = descending)")
+}
+
+// addSearchFlags registers the /ads_archive filter flags shared by every command
+// that runs a search (search, export, ...). --limit and --fields are left to each
+// command since their defaults differ.
+func addSearchFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&searchOpts.Query, "query", "", "Search terms to find in ad creative text")
+	fs.StringArrayVar(&searchOpts.Countries, "country", nil, "Country code(s) (ISO 3166, e.g. US, DE, FR). Repeatable.")
+	fs.StringArrayVar(&searchOpts.PageIDs, "page-id", nil, "Facebook Page ID(s) to search. Repeatable.")
+	fs.StringArrayVar(&searchOpts.AdTypes, "type", nil, "Ad type: ALL (default) or POLITICAL_AND_ISSUE_ADS. Repeatable: runs once per type and merges.")
+	fs.StringVar(&searchOpts.Status, "status", "ALL", "Ad active status: ALL, ACTIVE, or INACTIVE")
+	fs.BoolVar(&searchOpts.ActiveOnly, "active-only", false, "Only currently running ads (same as --status ACTIVE)")
+	fs.BoolVar(&searchOpts.IncludeInactive, "include-inactive", false, "Running and stopped ads, the default (same as --status ALL)")
+	fs.StringVar(&searchOpts.DateMin, "since", "", "Minimum delivery start date (YYYY-MM-DD, today, yesterday, 30d, 4w)")
+	fs.StringVar(&searchOpts.DateMax, "until", "", "Maximum delivery start date (YYYY-MM-DD, today, yesterday, 30d, 4w)")
+	fs.StringArrayVar(&searchOpts.Platforms, "platform", nil, "Platform filter: facebook, instagram, audience_network, messenger, threads. Repeatable.")
+	fs.StringArrayVar(&searchOpts.Languages, "language", nil, "Language filter (ISO 639-1, e.g. en, fr; case-insensitive). Repeatable.")
+	fs.StringVar(&searchOpts.MediaType, "media-type", "", "Filter by media type: ALL, IMAGE, MEME, VIDEO, NONE")
+	fs.BoolVar(&searchOpts.HasImage, "has-image", false, "Keep only ads with creative image URLs (filtered after fetching)")
+	fs.BoolVar(&searchOpts.HasVideo, "has-video", false, "Keep only video ads (same as --media-type VIDEO)")
+	fs.BoolVar(&searchOpts.StrictCountry, "strict-country", false, "Keep only ads whose reach breakdown includes a --country (filtered after fetching)")
+	fs.Int64Var(&searchOpts.MinImpressions, "min-impressions", 0, "Keep only ads whose estimated impressions lower bound is at least this")
+	fs.Int64Var(&searchOpts.MaxImpressions, "max-impressions", 0, "Keep only ads whose estimated impressions lower bound is at most this")
+	fs.BoolVar(&searchOpts.UnmaskRemoved, "unmask-removed", false, "Show the content of ads removed for violating standards (requires researcher access from Meta)")
+	fs.BoolVar(&searchOpts.IncludeNoImpressions, "include-no-impressions", false, "Keep ads without impressions data when filtering or sorting by impressions")
+}
+
 func runSearch(cmd *cobra.Command, args []string) error {
-	if len(searchCountries) == 0 {
-		return fmt.Errorf("at least one --country is required (e.g. --country US)")
+	if searchPreset != "" {
+		if err := applyPreset(cmd, searchPreset); err != nil {
+			return err
+		}
 	}
-	if searchQuery == "" && len(searchPageIDs) == 0 {
-		return fmt.Errorf("at least one of --query or --page-id is required")
+	if err := checkColumns(); err != nil {
+		return err
 	}
-
-	params := url.Values{}
-	params.Set("fields", searchFields)
-	params.Set("ad_type", searchAdType)
-	params.Set("ad_active_status", searchStatus)
-
-	// Countries as JSON array: ["US","DE"]
-	countriesJSON := toJSONArray(searchCountries)
-	params.Set("ad_reached_countries", countriesJSON)
-
-	if searchQuery != "" {
-		params.Set("search_terms", searchQuery)
+	if err := checkConvertTo(); err != nil {
+		return err
+	}
+	if err := checkSpendTiers(); err != nil {
+		return err
+	}
+	if searchOpts.Sample < 0 {
+		return usageErrorf("--sample must not be negative")
+	}
+	if searchOpts.MaxPages < 0 {
+		return usageErrorf("--max-pages must not be negative")
+	}
+	if searchOpts.Sample > 0 && searchOpts.MaxPages == 0 {
+		return usageErrorf("--sample needs --max-pages, to bound how many pages are read")
+	}
+	if searchOpts.Sample > 0 {
+		seed := searchSeed
+		if !cmd.Flags().Changed("seed") {
+			seed = rand.Uint64()
+		}
+		searchOpts.rng = rand.New(rand.NewPCG(seed, 0))
+	}
+	searchLimit = withDefaultLimit(cmd, searchLimit)
+	if searchOpts.FirstPageOnly && !cmd.Flags().Changed("limit") {
+		searchLimit = 0 // a full page
+	}
+	if searchOpts.Sample > 0 {
+		searchLimit = 0 // the sample size is the cap
 	}
 
-	if len(searchPageIDs) > 0 {
-		params.Set("search_page_ids", toJSONArray(searchPageIDs))
+	for _, name := range searchPageNames {
+		id, err := resolvePageName(name)
+		if err != nil {
+			return err
+		}
+		searchOpts.PageIDs = append(searchOpts.PageIDs, id)
 	}
 
-	if searchDateMin != "" {
-		params.Set("ad_delivery_date_min", searchDateMin)
+	fields := searchFields
+	if searchAllFields {
+		fields = api.FieldsAll
 	}
-	if searchDateMax != "" {
-		params.Set("ad_delivery_date_max", searchDateMax)
+	if searchURLsOnly {
+		fields = withField(fields, "ad_snapshot_url")
 	}
-
-	if len(searchPlatforms) > 0 {
-		params.Set("publisher_platforms", toJSONArray(searchPlatforms))
+	if searchSort != "" {
+		if err := checkChoice("sort", searchSort, validSorts); err != nil {
+			return err
+		}
+		fields = withField(fields, strings.TrimPrefix(searchSort, "-"))
+		if searchSort == "spend" || searchSort == "-spend" {
+			fields = withField(fields, "currency")
+		}
+	}
+	if searchCount {
+		fields = "id"
+	}
+	queries := []string{searchOpts.Query}
+	if searchQueriesFile != "" {
+		var err error
+		if queries, err = readQueriesFile(searchQueriesFile); err != nil {
+			return err
+		}
 	}
+	var runs []queryRun
+	var typed []typedParams
+	for _, q := range queries {
+		f := searchOpts
+		f.Query = q
+		params, err := f.params(fields)
+		if err != nil {
+			return err
+		}
+		if err := applyRawParams(params, searchRawParams); err != nil {
+			return err
+		}
+		runs = append(runs, queryRun{q, params})
+		typed = append(typed, searchOpts.paramsByType(params)...)
+	}
+	params := runs[0].params
 
-	if len(searchLanguages) > 0 {
-		params.Set("languages", toJSONArray(searchLanguages))
+	if searchDryRun {
+		for _, tp := range typed {
+			if err := printDryRun(client.SearchURL(tp.params, searchOpts.searchOptions(searchLimit))); err != nil {
+				return err
+			}
+		}
+		return nil
 	}
 
-	if searchMediaType != "" {
-		params.Set("ad_creative_media_type", searchMediaType)
+	if searchCount {
+		return runSearchCount(cmd, params)
 	}
 
-	items, err := client.SearchAds(params, searchLimit)
+	noteLimitCost(typed, searchOpts.searchOptions(searchLimit))
+	var res *searchResult
+	var err error
+	if searchQueriesFile != "" {
+		res, err = searchOpts.searchQueries(runs, searchLimit)
+	} else {
+		res, err = searchOpts.searchAds(params, searchLimit)
+	}
 	if err != nil {
 		return err
 	}
+	adQueries = res.queries
+	items := filterSpendTiers(res.items)
+	switch {
+	case res.maxPagesReached:
+		slog.Warn(fmt.Sprintf("stopped after --max-pages %d; more results are available", searchOpts.MaxPages))
+	case res.truncated:
+		warnTruncated(searchLimit, searchOpts.FirstPageOnly)
+	}
+	if searchSort != "" {
+		if items, err = sortAds(items, searchSort, searchOpts.IncludeNoImpressions); err != nil {
+			return err
+		}
+	}
+
+	if searchSaveLast {
+		saveLast(cmd, items)
+	}
+	archiveResults(cmd, searchArchiveQuery(), items)
+	// A failed --also-* file is reported after the results, which are
+	// printed anyway, as with --out-dir.
+	alsoErr := writeAlsoFiles(items)
+	if err := printSearchResults(cmd, items, params.Get("fields"), res.sources, runs); err != nil {
+		return err
+	}
+	return alsoErr
+}
+
+// printSearchResults prints the ads a search returned in the output format
+// asked for.
+func printSearchResults(cmd *cobra.Command, items []json.RawMessage, fields string, sources map[string]string, runs []queryRun) error {
+	if searchURLsOnly {
+		return printSnapshotURLs(items)
+	}
 
 	if len(items) == 0 {
 		if output.IsJSON(cmd) {
@@ -139,63 +367,736 @@ func runSearch(cmd *cobra.Command, args []string) error {
 
 	if output.IsJSON(cmd) {
 		// Wrap in array for clean JSON output
-		var raw []json.RawMessage
-		raw = append(raw, items...)
+		raw, err := adsJSON(items)
+		if err != nil {
+			return err
+		}
 		return output.PrintJSON(raw, output.IsPretty(cmd))
 	}
 
 	// Parse for table display
+	ads := parseAds(items)
+	noteUnrequestedColumns(fields)
+
+	printAdsTable(ads, sources)
+	fmt.Printf("\n%d ad(s) returned\n", len(ads))
+	if searchQueriesFile != "" {
+		printQueryCounts(ads, runs)
+	}
+	printAdsSummary(ads)
+	return nil
+}
+
+// searchArchiveQuery names a search in --out-dir file names: its
+// --queries-file name or --query, else its page names or IDs.
+func searchArchiveQuery() string {
+	switch {
+	case searchQueriesFile != "":
+		return queriesFileName()
+	case searchOpts.Query != "":
+		return searchOpts.Query
+	case len(searchPageNames) > 0:
+		return strings.Join(searchPageNames, " ")
+	}
+	return strings.Join(searchOpts.PageIDs, " ")
+}
+
+// adCount is the search --count output in JSON mode. Source is "total_count"
+// when Meta reported the total, or "paged" when the results were counted.
+type adCount struct {
+	Count  int    `json:"count"`
+	Source string `json:"source"`
+}
+
+// runSearchCount prints the number of ads matching params. Meta's total is
+// used when available; it can't be with post-fetch filters or several ad
+// types (whose results overlap), so those always page through the results.
+func runSearchCount(cmd *cobra.Command, params url.Values) error {
+	c := adCount{Source: "total_count"}
+	ok := false
+	if !searchOpts.filtersLocally() && len(searchOpts.paramsByType(params)) == 1 {
+		var err error
+		if c.Count, ok, err = client.Count(params); err != nil {
+			return err
+		}
+	}
+	if !ok {
+		slog.Debug("no total count available; paging through results")
+		res, err := searchOpts.searchAds(params, 0)
+		if err != nil {
+			return err
+		}
+		if res.maxPagesReached {
+			slog.Warn(fmt.Sprintf("stopped after --max-pages %d; the count is a lower bound", searchOpts.MaxPages))
+		}
+		c = adCount{Count: len(res.items), Source: "paged"}
+	}
+	if output.IsJSON(cmd) {
+		return output.PrintJSON(c, output.IsPretty(cmd))
+	}
+	fmt.Println(c.Count)
+	return nil
+}
+
+// adTypes returns the normalized --type values without duplicates, defaulting to ALL.
+func (f searchFilters) adTypes() ([]string, error) {
+	if len(f.AdTypes) == 0 {
+		return []string{"ALL"}, nil
+	}
+	var types []string
+	for _, t := range f.AdTypes {
+		v, err := normalizeUpper("type", t, validAdTypes)
+		if err != nil {
+			return nil, err
+		}
+		if !slices.Contains(types, v) {
+			types = append(types, v)
+		}
+	}
+	return types, nil
+}
+
+// typedParams is the query for one ad type of a multi-type search.
+type typedParams struct {
+	adType string
+	params url.Values
+}
+
+// paramsByType returns one query per --type. A single type keeps params as
+// built (including any --param override of ad_type).
+func (f searchFilters) paramsByType(params url.Values) []typedParams {
+	types, _ := f.adTypes() // already validated by params
+	if len(types) <= 1 {
+		return []typedParams{{params.Get("ad_type"), params}}
+	}
+	out := make([]typedParams, len(types))
+	for i, t := range types {
+		p := url.Values{}
+		for k, v := range params {
+			p[k] = v
+		}
+		p.Set("ad_type", t)
+		out[i] = typedParams{t, p}
+	}
+	return out
+}
+
+// searchResult is the merged outcome of a search across --type values.
+type searchResult struct {
+	items []json.RawMessage
+	// sources maps each ad ID to the type that returned it; nil unless
+	// several types were searched.
+	sources map[string]string
+	// truncated is true when the limit cut off further results.
+	truncated bool
+	// maxPagesReached is true when --max-pages cut off further results.
+	maxPagesReached bool
+	// deadlineReached is true when --deadline stopped the search, so items
+	// are partial.
+	deadlineReached bool
+	// queries records which query found each ad; nil unless several
+	// queries were run (--queries-file).
+	queries queryTags
+}
+
+// searchAds runs the search once per --type and merges the results in order,
+// dropping ads already returned for an earlier type, up to limit (0 = all).
+func (f searchFilters) searchAds(params url.Values, limit int) (*searchResult, error) {
+	queries := f.paramsByType(params)
+	opts := f.searchOptions(limit)
+	var counts keptCounts
+	if opts.Sample > 0 {
+		// Filter before sampling, so filters don't shrink the sample.
+		opts.SampleFilter = func(items []json.RawMessage) []json.RawMessage {
+			kept, n := f.filter(items)
+			counts.dropped += n.dropped
+			counts.unknown += n.unknown
+			counts.unparsed += n.unparsed
+			return filterSpendTiers(kept)
+		}
+	}
+	keep := func(items []json.RawMessage) []json.RawMessage {
+		if opts.Sample > 0 {
+			f.noteKept(counts)
+			return items
+		}
+		return f.keep(items)
+	}
+	if len(queries) == 1 {
+		res, err := client.Search(params, opts)
+		if err != nil {
+			return nil, err
+		}
+		return &searchResult{items: keep(res.Items), truncated: res.Truncated, maxPagesReached: res.MaxPagesReached, deadlineReached: res.DeadlineReached}, nil
+	}
+
+	out := &searchResult{sources: map[string]string{}}
+	for _, q := range queries {
+		res, err := client.Search(q.params, opts)
+		if err != nil {
+			return nil, fmt.Errorf("searching %s ads: %w", q.adType, err)
+		}
+		out.truncated = out.truncated || res.Truncated
+		out.maxPagesReached = out.maxPagesReached || res.MaxPagesReached
+		for _, item := range res.Items {
+			var rec struct {
+				ID string `json:"id"`
+			}
+			if json.Unmarshal(item, &rec) == nil && rec.ID != "" {
+				if _, dup := out.sources[rec.ID]; dup {
+					continue
+				}
+				out.sources[rec.ID] = q.adType
+			}
+			out.items = append(out.items, item)
+		}
+		// Later types would fail at once; keep what this one fetched.
+		if res.DeadlineReached {
+			out.deadlineReached = true
+			break
+		}
+	}
+	if limit > 0 && len(out.items) > limit {
+		out.items = out.items[:limit]
+		out.truncated = true
+	}
+	// Each type was sampled separately; draw the final sample from their union.
+	if f.Sample > 0 && len(out.items) > f.Sample {
+		f.rng.Shuffle(len(out.items), func(i, j int) { out.items[i], out.items[j] = out.items[j], out.items[i] })
+		out.items = out.items[:f.Sample]
+	}
+	out.items = keep(out.items)
+	return out, nil
+}
+
+// searchOptions returns the paging options for a search capped at limit.
+func (f searchFilters) searchOptions(limit int) api.SearchOptions {
+	opts := api.SearchOptions{Limit: limit, FirstPageOnly: f.FirstPageOnly, MaxPages: f.MaxPages}
+	if f.Sample > 0 {
+		opts.Limit = 0
+		opts.Sample = f.Sample
+		opts.Rand = f.rng
+	}
+	return opts
+}
+
+// manyPages is the number of requests above which noteLimitCost speaks up.
+const manyPages = 20
+
+// noteLimitCost explains up front what a large --limit costs: Meta serves at
+// most 2000 ads per page (100 unless --param limit says otherwise), so the
+// limit is reached in many requests, one per page, per ad type.
+func noteLimitCost(queries []typedParams, opts api.SearchOptions) {
+	if len(queries) == 0 || opts.Sample > 0 {
+		return
+	}
+	pages := api.PagesNeeded(queries[0].params, opts) * len(queries)
+	if pages <= manyPages {
+		return
+	}
+	perPage := (opts.Limit*len(queries) + pages - 1) / pages
+	slog.Info(fmt.Sprintf("--limit %d takes up to %d requests at %d ads per page; Meta serves at most 2000 per page. "+
+		"To fetch everything, use --limit 0 (with --max-pages to cap the requests)", opts.Limit, pages, perPage))
+}
+
+// warnTruncated tells the user that a result set was capped by --limit, or
+// by --first-page-only when firstPage is set.
+func warnTruncated(limit int, firstPage bool) {
+	if firstPage {
+		slog.Info("--first-page-only: more results are available")
+		return
+	}
+	slog.Warn(fmt.Sprintf("results truncated at %d; more available (increase --limit or use --limit 0)", limit))
+}
+
+// keep applies the filters Meta can't evaluate server-side (--has-image,
+// --strict-country), and notes what --strict-country did and how many ads
+// couldn't be parsed.
+func (f searchFilters) keep(items []json.RawMessage) []json.RawMessage {
+	kept, n := f.filter(items)
+	f.noteKept(n)
+	return kept
+}
+
+// keptCounts tallies what filter did.
+type keptCounts struct {
+	// dropped ads didn't reach the --strict-country countries; unknown ads
+	// had no reach data and were kept.
+	dropped, unknown int
+	// unparsed ads couldn't be decoded and were skipped.
+	unparsed int
+}
+
+// filter is keep without the notes, for callers that filter page by page.
+func (f searchFilters) filter(items []json.RawMessage) ([]json.RawMessage, keptCounts) {
+	var n keptCounts
+	if !f.filtersLocally() {
+		return items, n
+	}
+	type reach struct {
+		Country string `json:"country"`
+	}
+	countries := withDefaultCountry(f.Countries)
+	var kept []json.RawMessage
+	for _, item := range items {
+		var rec struct {
+			ImageURLs   []string        `json:"ad_creative_image_urls"`
+			Reach       []reach         `json:"age_country_gender_reach_breakdown"`
+			Impressions *api.RangeValue `json:"impressions"`
+		}
+		if err := json.Unmarshal(item, &rec); err != nil {
+			slog.Debug("skipping unparseable ad", "err", err, "record", bodySnippet(item))
+			n.unparsed++
+			continue
+		}
+		if f.HasImage && len(rec.ImageURLs) == 0 {
+			continue
+		}
+		if f.filtersImpressions() {
+			v, ok := rec.Impressions.Lower()
+			if !ok && !f.IncludeNoImpressions {
+				continue
+			}
+			if ok && (v < float64(f.MinImpressions) || (f.MaxImpressions > 0 && v > float64(f.MaxImpressions))) {
+				continue
+			}
+		}
+		if f.StrictCountry {
+			reached := slices.ContainsFunc(rec.Reach, func(r reach) bool {
+				return slices.ContainsFunc(countries, func(c string) bool {
+					return strings.EqualFold(c, r.Country)
+				})
+			})
+			switch {
+			case len(rec.Reach) == 0:
+				n.unknown++
+			case !reached:
+				n.dropped++
+				continue
+			}
+		}
+		kept = append(kept, item)
+	}
+	return kept, n
+}
+
+// noteKept reports what --strict-country did and how many ads were skipped
+// as unparseable.
+func (f searchFilters) noteKept(n keptCounts) {
+	warnUnparsed(n.unparsed)
+	if n.dropped > 0 {
+		slog.Info(fmt.Sprintf("--strict-country removed %d ad(s) not reaching %s", n.dropped, strings.Join(withDefaultCountry(f.Countries), ", ")))
+	}
+	if n.unknown > 0 {
+		slog.Info(fmt.Sprintf("%d ad(s) have no per-country reach data and were kept", n.unknown))
+	}
+}
+
+// filtersLocally reports whether keep drops anything, i.e. whether some
+// filter is applied after fetching.
+func (f searchFilters) filtersLocally() bool {
+	return f.HasImage || f.StrictCountry || f.filtersImpressions()
+}
+
+// filtersImpressions reports whether --min-impressions or --max-impressions is set.
+func (f searchFilters) filtersImpressions() bool {
+	return f.MinImpressions > 0 || f.MaxImpressions > 0
+}
+
+// params validates the filters and turns them into /ads_archive query
+// parameters requesting the given fields.
+func (f searchFilters) params(fields string) (url.Values, error) {
+	if err := checkFields(fields); err != nil {
+		return nil, err
+	}
+	f.Countries = withDefaultCountry(f.Countries)
+	if len(f.Countries) == 0 {
+		return nil, errNoCountry
+	}
+	if f.Query == "" && len(f.PageIDs) == 0 {
+		return nil, usageErrorf("at least one of --query or --page-id is required")
+	}
+	adTypes, err := f.adTypes()
+	if err != nil {
+		return nil, err
+	}
+	status, err := normalizeStatus(f.Status, f.ActiveOnly, f.IncludeInactive)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range f.Platforms {
+		if err := checkChoice("platform", p, validPlatforms); err != nil {
+			return nil, err
+		}
+	}
+	if f.MediaType != "" {
+		if err := checkChoice("media-type", f.MediaType, validMediaTypes); err != nil {
+			return nil, err
+		}
+	}
+	if f.HasVideo {
+		// Meta returns no per-ad media type, so video is only knowable server-side.
+		if f.MediaType != "" && f.MediaType != "VIDEO" {
+			return nil, usageErrorf("--has-video conflicts with --media-type %s", f.MediaType)
+		}
+		f.MediaType = "VIDEO"
+	}
+	if f.HasImage {
+		fields = withField(fields, "ad_creative_image_urls")
+	}
+	if f.StrictCountry {
+		fields = withField(fields, "age_country_gender_reach_breakdown")
+	}
+	if f.MinImpressions < 0 || f.MaxImpressions < 0 {
+		return nil, usageErrorf("--min-impressions and --max-impressions must not be negative")
+	}
+	if f.MaxImpressions > 0 && f.MinImpressions > f.MaxImpressions {
+		return nil, usageErrorf("--min-impressions %d is above --max-impressions %d", f.MinImpressions, f.MaxImpressions)
+	}
+	if f.filtersImpressions() {
+		fields = withField(fields, "impressions")
+	}
+	if spendTiers != nil {
+		// The currency is needed to convert spend, or to warn that tiers
+		// mix currencies.
+		fields = withField(withField(fields, "spend"), "currency")
+	}
+
+	params := url.Values{}
+	params.Set("fields", fields)
+	params.Set("ad_type", adTypes[0])
+	params.Set("ad_active_status", status)
+
+	// Countries as JSON array: ["US","DE"]
+	params.Set("ad_reached_countries", toJSONArray(f.Countries))
+
+	if f.Query != "" {
+		params.Set("search_terms", f.Query)
+	}
+
+	if len(f.PageIDs) > 0 {
+		params.Set("search_page_ids", toJSONArray(f.PageIDs))
+	}
+
+	dateMin, dateMax, err := normalizeDateRange(f.DateMin, f.DateMax)
+	if err != nil {
+		return nil, err
+	}
+	if dateMin != "" {
+		params.Set("ad_delivery_date_min", dateMin)
+	}
+	if dateMax != "" {
+		params.Set("ad_delivery_date_max", dateMax)
+	}
+
+	if len(f.Platforms) > 0 {
+		params.Set("publisher_platforms", toJSONArray(f.Platforms))
+	}
+
+	if len(f.Languages) > 0 {
+		langs, err := normalizeLanguages(f.Languages)
+		if err != nil {
+			return nil, err
+		}
+		params.Set("languages", toJSONArray(langs))
+	}
+
+	if f.MediaType != "" {
+		params.Set("ad_creative_media_type", f.MediaType)
+	}
+	if f.UnmaskRemoved {
+		params.Set("unmask_removed_content", "true")
+	}
+
+	return params, nil
+}
+
+// resolvePageName finds the page ID for name. A single result, or a single
+// case-insensitive exact match, wins; otherwise the candidates are listed.
+func resolvePageName(name string) (string, error) {
+	pages, err := client.SearchPages(name)
+	if err != nil {
+		return "", fmt.Errorf("looking up page %q: %w", name, err)
+	}
+	if len(pages) == 0 {
+		return "", usageErrorf("no page found matching %q", name)
+	}
+	if len(pages) == 1 {
+		return pages[0].ID, nil
+	}
+
+	var exact []api.Page
+	for _, p := range pages {
+		if strings.EqualFold(p.Name, name) {
+			exact = append(exact, p)
+		}
+	}
+	if len(exact) == 1 {
+		return exact[0].ID, nil
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d pages match %q — pick one with --page-id:", len(pages), name)
+	for i, p := range pages {
+		if i == 10 {
+			fmt.Fprintf(&b, "\n  ... and %d more", len(pages)-i)
+			break
+		}
+		fmt.Fprintf(&b, "\n  %-20s %s", p.ID, p.Name)
+		if p.Category != "" {
+			fmt.Fprintf(&b, " (%s)", p.Category)
+		}
+	}
+	return "", usageError{errors.New(b.String())}
+}
+
+// parseAds decodes raw /ads_archive records into typed records. Records that
+// don't decode are skipped with a warning, so one malformed ad doesn't sink
+// the rest of the result set.
+func parseAds(items []json.RawMessage) []api.AdArchiveRecord {
 	ads := make([]api.AdArchiveRecord, 0, len(items))
+	bad := 0
 	for _, raw := range items {
 		var a api.AdArchiveRecord
 		if err := json.Unmarshal(raw, &a); err != nil {
-			return fmt.Errorf("parsing ad: %w", err)
+			slog.Debug("skipping unparseable ad", "err", err, "record", bodySnippet(raw))
+			bad++
+			continue
 		}
 		ads = append(ads, a)
 	}
+	warnUnparsed(bad)
+	return ads
+}
 
-	printAdsTable(ads)
-	fmt.Printf("\n%d ad(s) returned\n", len(ads))
-	return nil
+// warnUnparsed warns that n ads could not be parsed and were skipped.
+func warnUnparsed(n int) {
+	if n > 0 {
+		slog.Warn(fmt.Sprintf("%d ad(s) could not be parsed and were skipped", n))
+	}
+}
+
+// bodySnippet returns the start of raw for log messages.
+func bodySnippet(raw []byte) string {
+	const maxLen = 120
+	if len(raw) > maxLen {
+		return string(raw[:maxLen]) + "…"
+	}
+	return string(raw)
+}
+
+// sortAds orders items by the lower bound of their spend or impressions
+// estimate; a leading "-" in key sorts descending. Ads without the value go
+// last, except that ads without impressions are dropped when sorting by
+// impressions unless keepMissing is set. Note that spend is compared across
+// currencies as-is.
+func sortAds(items []json.RawMessage, key string, keepMissing bool) ([]json.RawMessage, error) {
+	desc := strings.HasPrefix(key, "-")
+	field := strings.TrimPrefix(key, "-")
+
+	type entry struct {
+		item  json.RawMessage
+		value float64
+		ok    bool
+	}
+	entries := make([]entry, 0, len(items))
+	for _, item := range items {
+		var rec api.AdArchiveRecord
+		// Records that don't decode sort last, like ads without the value.
+		_ = json.Unmarshal(item, &rec)
+		r := rec.Spend
+		if field == "impressions" {
+			r = rec.Impressions
+		} else if convertToFlag != "" {
+			// Ads without a rate sort last rather than mixing currencies.
+			r, _ = convertedSpend(rec)
+		}
+		v, ok := r.Lower()
+		if !ok && field == "impressions" && !keepMissing {
+			continue
+		}
+		entries = append(entries, entry{item, v, ok})
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		if a.ok != b.ok {
+			return a.ok
+		}
+		if desc {
+			return a.value > b.value
+		}
+		return a.value < b.value
+	})
+
+	out := make([]json.RawMessage, len(entries))
+	for i, e := range entries {
+		out[i] = e.item
+	}
+	return out, nil
+}
+
+// withField returns the comma-separated fields with name appended if missing.
+func withField(fields, name string) string {
+	if slices.Contains(strings.Split(fields, ","), name) {
+		return fields
+	}
+	return fields + "," + name
 }
 
-func printAdsTable(ads []api.AdArchiveRecord) {
-	headers := []string{"ID", "PAGE", "STARTED", "STATUS", "SPEND", "PLATFORMS", "BODY"}
+// printAdsTable prints one row per ad with the --columns layout. When sources
+// is non-nil (a multi-type search), a TYPE column shows which ad type returned
+// each ad.
+func printAdsTable(ads []api.AdArchiveRecord, sources map[string]string) {
+	cols := tableColumns()
+	var headers []string
+	for _, c := range cols {
+		headers = append(headers, adColumns[c].header)
+	}
+	if sources != nil {
+		headers = slices.Insert(headers, 1, "TYPE")
+	}
 	rows := make([][]string, len(ads))
+	queryOf := adQueries.tagger()
 	for i, a := range ads {
-		status := "inactive"
-		if a.AdDeliveryStopTime == "" {
-			status = "active"
+		query := queryOf(a.ID)
+		for _, c := range cols {
+			if c == "query" {
+				// The query depends on which copy of the ad this is,
+				// which a column's value can't tell.
+				rows[i] = append(rows[i], orDash(output.Truncate(query, 20)))
+				continue
+			}
+			rows[i] = append(rows[i], adColumns[c].value(a))
+		}
+		if sources != nil {
+			rows[i] = slices.Insert(rows[i], 1, orDash(sources[a.ID]))
 		}
+	}
+	output.PrintTable(headers, rows)
+}
 
-		body := "-"
-		if len(a.AdCreativeBodies) > 0 {
-			body = output.Truncate(a.AdCreativeBodies[0], 50)
-		} else if len(a.AdCreativeLinkTitles) > 0 {
-			body = output.Truncate(a.AdCreativeLinkTitles[0], 50)
+// printAdsSummary prints the total estimated spend (grouped by currency) and the
+// number of distinct pages across ads.
+func printAdsSummary(ads []api.AdArchiveRecord) {
+	pages := map[string]bool{}
+	for _, a := range ads {
+		if a.PageID != "" {
+			pages[a.PageID] = true
+		} else if a.PageName != "" {
+			pages[a.PageName] = true
 		}
+	}
+	fmt.Printf("total spend (est.): %s across %d page(s)\n", spendTotal(ads), len(pages))
+}
 
-		platforms := output.JoinStrings(a.PublisherPlatforms, ", ")
+// spendTotal sums the estimated spend of ads per currency, e.g.
+// "100–500 EUR, 2000+ USD", or "-" when no ad has spend data.
+func spendTotal(ads []api.AdArchiveRecord) string {
+	type total struct {
+		lower, upper float64
+		openEnded    bool
+	}
+	totals := map[string]*total{}
+	var currencies []string
 
-		spend := "-"
-		if a.Spend != nil {
-			spend = a.Spend.String()
-			if a.Currency != "" {
-				spend += " " + a.Currency
+	for _, a := range ads {
+		spend, cur := a.Spend, a.Currency
+		if convertToFlag != "" {
+			if r, ok := convertedSpend(a); ok {
+				spend, cur = r, convertToFlag+" (converted)"
 			}
 		}
+		lo, okLo := spend.Lower()
+		if !okLo {
+			continue
+		}
+		if cur == "" {
+			cur = "(unknown currency)"
+		}
+		t, ok := totals[cur]
+		if !ok {
+			t = &total{}
+			totals[cur] = t
+			currencies = append(currencies, cur)
+		}
+		t.lower += lo
+		if hi, okHi := spend.Upper(); okHi {
+			t.upper += hi
+		} else {
+			t.upper += lo
+			t.openEnded = true
+		}
+	}
 
-		rows[i] = []string{
-			a.ID,
-			output.Truncate(a.PageName, 25),
-			output.FormatTime(a.AdDeliveryStartTime),
-			status,
-			spend,
-			output.Truncate(platforms, 20),
-			body,
+	spend := "-"
+	if len(currencies) > 0 {
+		sort.Strings(currencies)
+		parts := make([]string, len(currencies))
+		for i, cur := range currencies {
+			t := totals[cur]
+			lo, hi := output.FormatNumber(fmt.Sprintf("%.0f", t.lower)), output.FormatNumber(fmt.Sprintf("%.0f", t.upper))
+			r := lo + "–" + hi
+			if t.lower == t.upper {
+				r = lo
+			}
+			if t.openEnded {
+				r += "+"
+			}
+			parts[i] = r + " " + cur
 		}
+		spend = strings.Join(parts, ", ")
 	}
-	output.PrintTable(headers, rows)
+	return spend
+}
+
+// errNoCountry is returned when neither --country nor default_country is set.
+var errNoCountry = usageErrorf("at least one --country is required (e.g. --country US), or set a default: meta-adlib config set default-country US")
+
+// withDefaultLimit returns limit, or the configured default_limit when the
+// --limit flag wasn't passed explicitly.
+func withDefaultLimit(cmd *cobra.Command, limit int) int {
+	if cmd.Flags().Changed("limit") {
+		return limit
+	}
+	if f, err := config.LoadFile(); err == nil && f.DefaultLimit != nil {
+		return *f.DefaultLimit
+	}
+	return limit
+}
+
+// withDefaultCountry returns countries, or the configured default_country when
+// none were given. Config errors are ignored here; they surface elsewhere.
+func withDefaultCountry(countries []string) []string {
+	if len(countries) > 0 {
+		return countries
+	}
+	if f, err := config.LoadFile(); err == nil && f.DefaultCountry != "" {
+		return []string{f.DefaultCountry}
+	}
+	return nil
+}
+
+// applyRawParams sets each key=value from --param on params, replacing any
+// value the CLI computed for the same key.
+func applyRawParams(params url.Values, raw []string) error {
+	for _, kv := range raw {
+		key, value, ok := strings.Cut(kv, "=")
+		if !ok || key == "" {
+			return usageErrorf("invalid --param %q — expected key=value", kv)
+		}
+		params.Set(key, value)
+	}
+	return nil
+}
+
+// printDryRun prints a request URL built for --dry-run.
+func printDryRun(reqURL string, err error) error {
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(output.Out, reqURL)
+	return err
 }
 
 // toJSONArray converts a slice of strings into a JSON array string, e.g. `["US","DE"]`.
@@ -0,0 +1,197 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/the20100/meta-ad-library-cli/internal/api"
+)
+
+var (
+	serveAddr          string
+	serveMaxConcurrent int
+)
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Run a local HTTP server exposing search and ad lookup as JSON",
+	Long: `Starts an HTTP server that wraps the Ad Library API using the same token
+resolution and paging as the CLI, so dashboards can query it without
+handling auth themselves.
+
+Endpoints:
+  GET /search   Query params mirror the search flags: query, country (repeatable),
+                page_id (repeatable), type (repeatable), status, since, until, platform
+                (repeatable), language (repeatable), media_type, fields, limit
+  GET /ad/{id}  Single ad details (optional: fields)
+  GET /healthz  Liveness check
+  GET /metrics  Prometheus metrics: Graph API requests, errors, rate-limit
+                warnings and latency, requests served per endpoint, and Go
+                runtime and process metrics
+
+At most --max-concurrent upstream requests run at once; extra requests get
+HTTP 429 with Retry-After.
+
+Examples:
+  meta-adlib serve --addr :8080
+  curl 'localhost:8080/search?query=shoes&country=US&limit=10'
+  curl localhost:8080/ad/123456789012345
+  curl localhost:8080/metrics`,
+	RunE: runServe,
+}
+
+func init() {
+	serveCmd.Flags().StringVar(&serveAddr, "addr", "127.0.0.1:8080", "Address to listen on")
+	serveCmd.Flags().IntVar(&serveMaxConcurrent, "max-concurrent", 4, "Maximum concurrent upstream requests")
+
+	rootCmd.AddCommand(serveCmd)
+}
+
+func runServe(cmd *cobra.Command, args []string) error {
+	if serveMaxConcurrent < 1 {
+		return usageErrorf("--max-concurrent must be at least 1")
+	}
+
+	srv := &http.Server{
+		Addr:              serveAddr,
+		Handler:           newServeMux(make(chan struct{}, serveMaxConcurrent)),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	errCh := make(chan error, 1)
+	go func() { errCh <- srv.ListenAndServe() }()
+	slog.Info(fmt.Sprintf("listening on %s", serveAddr))
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		return srv.Shutdown(shutdownCtx)
+	}
+}
+
+// newServeMux builds the HTTP routes. sem bounds concurrent upstream calls.
+func newServeMux(sem chan struct{}) *http.ServeMux {
+	limited := func(h http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+				h(w, r)
+			default:
+				w.Header().Set("Retry-After", "1")
+				writeJSONError(w, http.StatusTooManyRequests, errors.New("too many concurrent requests"))
+			}
+		}
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+	})
+	mux.Handle("GET /metrics", serveStats.handler())
+	mux.HandleFunc("GET /search", serveStats.counted("search", limited(handleSearch)))
+	mux.HandleFunc("GET /ad/{id}", serveStats.counted("ad", limited(handleAdGet)))
+	return mux
+}
+
+func handleSearch(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	f := searchFilters{
+		Query:     q.Get("query"),
+		Countries: q["country"],
+		PageIDs:   q["page_id"],
+		AdTypes:   q["type"],
+		Status:    valueOr(q, "status", "ALL"),
+		DateMin:   q.Get("since"),
+		DateMax:   q.Get("until"),
+		Platforms: q["platform"],
+		Languages: q["language"],
+		MediaType: q.Get("media_type"),
+	}
+	limit := 25
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			writeJSONError(w, http.StatusBadRequest, fmt.Errorf("invalid limit %q", v))
+			return
+		}
+		limit = n
+	}
+
+	params, err := f.params(valueOr(q, "fields", api.FieldsSummary))
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	res, err := f.searchAds(params, limit)
+	if err != nil {
+		writeUpstreamError(w, err)
+		return
+	}
+	items := res.items
+	if res.truncated {
+		w.Header().Set("X-Results-Truncated", "true")
+	}
+	if items == nil {
+		items = []json.RawMessage{}
+	}
+	writeJSON(w, http.StatusOK, items)
+}
+
+func handleAdGet(w http.ResponseWriter, r *http.Request) {
+	fields := strings.Split(valueOr(r.URL.Query(), "fields", api.FieldsDetail), ",")
+
+	_, body, err := client.GetAd(r.PathValue("id"), fields)
+	if err != nil {
+		writeUpstreamError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, json.RawMessage(body))
+}
+
+// valueOr returns q[key], or def when the key is absent or empty.
+func valueOr(q url.Values, key, def string) string {
+	if v := q.Get(key); v != "" {
+		return v
+	}
+	return def
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v) //nolint:errcheck
+}
+
+func writeJSONError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
+
+// writeUpstreamError reports a failed Graph API call: Meta errors as 502 with
+// their details, anything else (network, timeouts) as 504.
+func writeUpstreamError(w http.ResponseWriter, err error) {
+	var metaErr *api.MetaError
+	if errors.As(err, &metaErr) {
+		writeJSON(w, http.StatusBadGateway, map[string]any{"error": metaErr.Error(), "meta": metaErr})
+		return
+	}
+	writeJSONError(w, http.StatusGatewayTimeout, err)
+}
@@ -0,0 +1,203 @@
+package cmd
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/the20100/meta-ad-library-cli/internal/logging"
+)
+
+var (
+	adSnapshotOut    string
+	adSnapshotImages bool
+)
+
+var snapshotHTTPClient = &http.Client{Timeout: 60 * time.Second}
+
+var adSnapshotCmd = &cobra.Command{
+	Use:   "snapshot <ad_archive_id>",
+	Short: "Save an ad's rendered snapshot page as HTML",
+	Long: `Looks up the ad's ad_snapshot_url, fetches the rendered snapshot page and
+saves the HTML to --out (default <id>.html).
+
+Meta only renders the snapshot for a valid access token, so the token is
+added to the URL's access_token parameter for the download. It is not
+written to the saved file.
+
+With --images, images referenced by <img> tags are downloaded next to the
+HTML file (into <name>_files/), --concurrency at a time, and the page is
+rewritten to point at the local copies, so it can be viewed offline.
+
+Examples:
+  meta-adlib ad snapshot 123456789012345
+  meta-adlib ad snapshot 123456789012345 --out ad.html --images`,
+	Args: cobra.ExactArgs(1),
+	RunE: runAdSnapshot,
+}
+
+func init() {
+	adSnapshotCmd.Flags().StringVarP(&adSnapshotOut, "out", "o", "", "File to write the HTML to (default <id>.html)")
+	adSnapshotCmd.Flags().BoolVar(&adSnapshotImages, "images", false, "Also download referenced images and link them locally")
+
+	adCmd.AddCommand(adSnapshotCmd)
+}
+
+func runAdSnapshot(cmd *cobra.Command, args []string) error {
+	id := args[0]
+	out := adSnapshotOut
+	if out == "" {
+		out = id + ".html"
+	}
+
+	ad, _, err := client.GetAd(id, []string{"id", "ad_snapshot_url"})
+	if err != nil {
+		return err
+	}
+	if ad.AdSnapshotURL == "" {
+		return fmt.Errorf("ad %s has no ad_snapshot_url", id)
+	}
+
+	snapURL, err := client.SnapshotURL(ad.AdSnapshotURL)
+	if err != nil {
+		return fmt.Errorf("invalid ad_snapshot_url: %w", err)
+	}
+	page, err := fetchSnapshot(snapURL)
+	if err != nil {
+		return fmt.Errorf("fetching snapshot: %w", err)
+	}
+
+	body := string(page)
+	if adSnapshotImages {
+		body, err = saveSnapshotImages(body, snapURL, out)
+		if err != nil {
+			return err
+		}
+	}
+	// The rendered page can echo the request URL; keep the token out of the file.
+	body = redactToken(body)
+
+	if err := os.WriteFile(out, []byte(body), 0o644); err != nil {
+		return fmt.Errorf("writing %s: %w", out, err)
+	}
+	slog.Info(fmt.Sprintf("saved snapshot of ad %s to %s", id, out))
+	return nil
+}
+
+// fetchSnapshot GETs reqURL and returns the body, failing on non-2xx statuses.
+// redactToken replaces the access token in s, including its URL-encoded and
+// HTML-escaped forms.
+func redactToken(s string) string {
+	token := client.Token()
+	if token == "" {
+		return s
+	}
+	for _, t := range []string{token, url.QueryEscape(token), html.EscapeString(token)} {
+		s = strings.ReplaceAll(s, t, "REDACTED")
+	}
+	return s
+}
+
+func fetchSnapshot(reqURL string) ([]byte, error) {
+	req, err := http.NewRequest(http.MethodGet, reqURL, nil) //nolint:noctx
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", userAgent())
+	resp, err := snapshotHTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+	return body, nil
+}
+
+var imgSrcRe = regexp.MustCompile(`(<img\b[^>]*?\bsrc=")([^"]+)(")`)
+
+// saveSnapshotImages downloads the images referenced by <img src="..."> in
+// page into a <out>_files directory, --concurrency at a time, and returns page
+// with the src attributes pointing at the local copies. Images that fail to
+// download keep their original URL and are reported on stderr.
+func saveSnapshotImages(page, pageURL, out string) (string, error) {
+	base, err := url.Parse(pageURL)
+	if err != nil {
+		return "", err
+	}
+	dir := strings.TrimSuffix(out, filepath.Ext(out)) + "_files"
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("creating %s: %w", dir, err)
+	}
+
+	// Collect each distinct image once, in page order.
+	var srcs []string
+	index := map[string]int{}
+	for _, m := range imgSrcRe.FindAllStringSubmatch(page, -1) {
+		src := html.UnescapeString(m[2])
+		if _, ok := index[src]; ok || strings.HasPrefix(src, "data:") {
+			continue
+		}
+		index[src] = len(srcs)
+		srcs = append(srcs, src)
+	}
+
+	local := make([]string, len(srcs))
+	runPool(len(srcs), concurrencyFlag, func(i int) {
+		ref, err := base.Parse(srcs[i])
+		if err != nil {
+			return
+		}
+		data, err := fetchSnapshot(ref.String())
+		if err == nil {
+			name := fmt.Sprintf("image%d%s", i+1, imageExt(ref.Path))
+			if err = os.WriteFile(filepath.Join(dir, name), data, 0o644); err == nil {
+				local[i] = filepath.ToSlash(filepath.Join(filepath.Base(dir), name))
+				return
+			}
+		}
+		slog.Warn("image download failed", "url", logging.RedactURL(ref.String()), "err", redactToken(err.Error()))
+	})
+
+	n := 0
+	for _, l := range local {
+		if l != "" {
+			n++
+		}
+	}
+	page = imgSrcRe.ReplaceAllStringFunc(page, func(tag string) string {
+		m := imgSrcRe.FindStringSubmatch(tag)
+		i, ok := index[html.UnescapeString(m[2])]
+		if !ok || local[i] == "" {
+			return tag
+		}
+		return m[1] + local[i] + m[3]
+	})
+	slog.Info(fmt.Sprintf("saved %d image(s) to %s", n, dir))
+	return page, nil
+}
+
+// imageExt returns the file extension of an image URL path, defaulting to .jpg.
+func imageExt(p string) string {
+	switch ext := strings.ToLower(path.Ext(p)); ext {
+	case ".jpg", ".jpeg", ".png", ".gif", ".webp", ".svg":
+		return ext
+	}
+	return ".jpg"
+}
@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/spf13/pflag"
+	"github.com/the20100/meta-ad-library-cli/internal/api"
+	"github.com/the20100/meta-ad-library-cli/internal/output"
+)
+
+// withTokenFlag adds the access token to snapshot URLs shown to the user.
+// It is off by default so the token doesn't end up in files, shell history
+// or shared screenshots.
+var withTokenFlag bool
+
+func addWithTokenFlag(fs *pflag.FlagSet) {
+	fs.BoolVar(&withTokenFlag, "with-token", false, "Add your access token to printed snapshot URLs so they open directly (don't share the output)")
+}
+
+// snapshotLink returns raw with the access token set when --with-token is
+// given (see api.Client.SnapshotURL), else raw unchanged.
+func snapshotLink(raw string) (string, error) {
+	if !withTokenFlag || client == nil || raw == "" {
+		return raw, nil
+	}
+	return client.SnapshotURL(raw)
+}
+
+// snapshotRow is the detail-view row for the snapshot URL, labelled when it
+// carries the access token.
+func snapshotRow(a api.AdArchiveRecord) []string {
+	if withTokenFlag && client != nil && a.AdSnapshotURL != "" {
+		if u, err := client.SnapshotURL(a.AdSnapshotURL); err == nil {
+			return []string{"Snapshot URL (with token)", output.Hyperlink(u, u)}
+		}
+	}
+	return []string{"Snapshot URL", output.Hyperlink(a.AdSnapshotURL, a.AdSnapshotURL)}
+}
+
+// printSnapshotURLs prints the ad_snapshot_url of each item, one per line, so
+// the output can be piped straight to xargs. Ads without a URL are skipped.
+func printSnapshotURLs(items []json.RawMessage) error {
+	for _, item := range items {
+		var rec struct {
+			URL string `json:"ad_snapshot_url"`
+		}
+		if err := json.Unmarshal(item, &rec); err != nil {
+			return fmt.Errorf("parsing ad: %w", err)
+		}
+		if rec.URL == "" {
+			continue
+		}
+		u, err := snapshotLink(rec.URL)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(output.Out, u)
+	}
+	return nil
+}
@@ -0,0 +1,189 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/the20100/meta-ad-library-cli/internal/api"
+	"github.com/the20100/meta-ad-library-cli/internal/output"
+)
+
+// statsBarWidth is the length, in cells, of the longest histogram bar.
+const statsBarWidth = 40
+
+var (
+	statsGroupBy     string
+	statsLimit       int
+	statsInput       string
+	statsInputFormat string
+)
+
+var statsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Histogram of ad delivery starts over time",
+	Long: `Runs a search (same filters as the search command) and counts ads per
+day, week, or month of ad_delivery_start_time. Empty buckets between the first
+and last start are included so gaps are visible.
+
+Weeks are ISO weeks (starting Monday), labelled YYYY-Www.
+
+With --input, ads are read from a file instead of searching: a JSON array
+(search --json) or NDJSON (export ndjson), optionally gzipped. No token is
+needed then, and the search filters are ignored.
+
+Examples:
+  meta-adlib stats --query "election" --country US --group-by week
+  meta-adlib stats --page-id 123456789 --country DE --group-by month --limit 0
+  meta-adlib stats --query "climate" --country FR --json
+  meta-adlib stats --input ads.ndjson.gz --group-by week`,
+	RunE: runStats,
+}
+
+func init() {
+	addSearchFlags(statsCmd.Flags())
+	statsCmd.Flags().StringVar(&statsGroupBy, "group-by", "month", "Bucket size: day, week, or month")
+	statsCmd.Flags().IntVar(&statsLimit, "limit", 1000, "Maximum number of ads to analyse (0 = fetch all pages)")
+	statsCmd.Flags().StringVar(&statsInput, "input", "", "Read ads from this file (\"-\" = stdin) instead of searching")
+	statsCmd.Flags().StringVar(&statsInputFormat, "input-format", "auto", "Format of --input: auto, json (array), or jsonl (one ad per line)")
+
+	rootCmd.AddCommand(statsCmd)
+}
+
+// bucketCount is one histogram row.
+type bucketCount struct {
+	Bucket string `json:"bucket"`
+	Count  int    `json:"count"`
+}
+
+func runStats(cmd *cobra.Command, args []string) error {
+	if err := checkChoice("group-by", statsGroupBy, []string{"day", "week", "month"}); err != nil {
+		return err
+	}
+
+	var items []json.RawMessage
+	if statsInput != "" {
+		var err error
+		if items, err = readAdsFile(statsInput, statsInputFormat); err != nil {
+			return err
+		}
+	} else {
+		params, err := searchOpts.params("id,ad_delivery_start_time")
+		if err != nil {
+			return err
+		}
+
+		res, err := searchOpts.searchAds(params, statsLimit)
+		if err != nil {
+			return err
+		}
+		items = res.items
+		if res.truncated {
+			warnTruncated(statsLimit, false)
+		}
+	}
+	ads := parseAds(items)
+
+	buckets, skipped := bucketAds(ads, statsGroupBy)
+
+	if output.IsJSON(cmd) {
+		if buckets == nil {
+			buckets = []bucketCount{}
+		}
+		return output.PrintJSON(buckets, output.IsPretty(cmd))
+	}
+
+	if len(buckets) == 0 {
+		fmt.Println("no ads found")
+		return nil
+	}
+
+	peak := 0
+	for _, b := range buckets {
+		peak = max(peak, b.Count)
+	}
+	rows := make([][]string, len(buckets))
+	for i, b := range buckets {
+		rows[i] = []string{b.Bucket, strconv.Itoa(b.Count), output.Bar(float64(b.Count), float64(peak), statsBarWidth)}
+	}
+	output.PrintTable([]string{strings.ToUpper(statsGroupBy), "ADS", ""}, rows)
+
+	fmt.Printf("\n%d ad(s) in %d %s bucket(s)\n", len(ads)-skipped, len(buckets), statsGroupBy)
+	if skipped > 0 {
+		fmt.Printf("%d ad(s) skipped (no delivery start time)\n", skipped)
+	}
+	return nil
+}
+
+// bucketAds counts ads per groupBy bucket of their delivery start, filling
+// empty buckets between the first and last. It also returns how many ads had
+// no parseable start time.
+func bucketAds(ads []api.AdArchiveRecord, groupBy string) ([]bucketCount, int) {
+	counts := map[time.Time]int{}
+	var first, last time.Time
+	skipped := 0
+	for _, a := range ads {
+		if len(a.AdDeliveryStartTime) < 10 {
+			skipped++
+			continue
+		}
+		t, err := time.Parse("2006-01-02", a.AdDeliveryStartTime[:10])
+		if err != nil {
+			skipped++
+			continue
+		}
+		start := bucketStart(t, groupBy)
+		counts[start]++
+		if first.IsZero() || start.Before(first) {
+			first = start
+		}
+		if start.After(last) {
+			last = start
+		}
+	}
+	if first.IsZero() {
+		return nil, skipped
+	}
+
+	var out []bucketCount
+	for t := first; !t.After(last); t = nextBucket(t, groupBy) {
+		out = append(out, bucketCount{Bucket: bucketLabel(t, groupBy), Count: counts[t]})
+	}
+	return out, skipped
+}
+
+// bucketStart truncates t to the start of its day, ISO week, or month.
+func bucketStart(t time.Time, groupBy string) time.Time {
+	switch groupBy {
+	case "week":
+		offset := (int(t.Weekday()) + 6) % 7 // days since Monday
+		return t.AddDate(0, 0, -offset)
+	case "month":
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	}
+	return t
+}
+
+func nextBucket(t time.Time, groupBy string) time.Time {
+	switch groupBy {
+	case "week":
+		return t.AddDate(0, 0, 7)
+	case "month":
+		return t.AddDate(0, 1, 0)
+	}
+	return t.AddDate(0, 0, 1)
+}
+
+func bucketLabel(t time.Time, groupBy string) string {
+	switch groupBy {
+	case "week":
+		y, w := t.ISOWeek()
+		return fmt.Sprintf("%d-W%02d", y, w)
+	case "month":
+		return t.Format("2006-01")
+	}
+	return t.Format("2006-01-02")
+}
@@ -0,0 +1,228 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/pflag"
+	"github.com/the20100/meta-ad-library-cli/internal/api"
+)
+
+var (
+	// spendTierFlag holds --spend-tier: the tiers to keep (nil = all).
+	spendTierFlag []string
+	// spendTiersFlag holds --spend-tiers, replacing defaultSpendTiers.
+	spendTiersFlag string
+	// spendTiers is the parsed tier table; nil unless tiers are in use.
+	spendTiers []spendTier
+)
+
+// defaultSpendTiers is the tier table used unless --spend-tiers is given.
+const defaultSpendTiers = "micro:<100,mid:100-1000,high:>1000"
+
+// spendTier is a named band of estimated spend. An ad falls in the first tier
+// whose band contains the midpoint of its spend range.
+type spendTier struct {
+	name string
+	// min and max bound the midpoint; minOpen and maxOpen exclude the bound
+	// itself. hasMin and hasMax are false for an unbounded side.
+	min, max         float64
+	hasMin, hasMax   bool
+	minOpen, maxOpen bool
+}
+
+func (t spendTier) contains(v float64) bool {
+	if t.hasMin && (v < t.min || (t.minOpen && v == t.min)) {
+		return false
+	}
+	if t.hasMax && (v > t.max || (t.maxOpen && v == t.max)) {
+		return false
+	}
+	return true
+}
+
+func addSpendTierFlags(fs *pflag.FlagSet) {
+	fs.StringSliceVar(&spendTierFlag, "spend-tier", nil, "Keep only ads in these spend tiers (default tiers: micro <100, mid 100-1000, high >1000) and label each ad's tier")
+	fs.StringVar(&spendTiersFlag, "spend-tiers", "", "Custom spend tiers as name:band pairs, bands being <N, <=N, A-B, >=N, or >N (e.g. \"small:<500,large:>=500\")")
+}
+
+// checkSpendTiers parses --spend-tiers and validates --spend-tier. Tiers are
+// used, and ads labelled, when either flag is given or --columns names
+// spend_tier.
+func checkSpendTiers() error {
+	if len(spendTierFlag) == 0 && spendTiersFlag == "" && !slices.Contains(columnsFlag, "spend_tier") {
+		return nil
+	}
+	def := spendTiersFlag
+	if def == "" {
+		def = defaultSpendTiers
+	}
+	tiers, err := parseSpendTiers(def)
+	if err != nil {
+		return usageErrorf("--spend-tiers: %v", err)
+	}
+	names := make([]string, len(tiers))
+	for i, t := range tiers {
+		names[i] = t.name
+	}
+	for i, name := range spendTierFlag {
+		spendTierFlag[i] = strings.ToLower(strings.TrimSpace(name))
+		if err := checkChoice("spend-tier", spendTierFlag[i], names); err != nil {
+			return err
+		}
+	}
+	spendTiers = tiers
+	return nil
+}
+
+// parseSpendTiers parses a comma-separated list of name:band pairs.
+func parseSpendTiers(def string) ([]spendTier, error) {
+	var tiers []spendTier
+	for _, part := range strings.Split(def, ",") {
+		name, band, ok := strings.Cut(strings.TrimSpace(part), ":")
+		name = strings.ToLower(strings.TrimSpace(name))
+		if !ok || name == "" {
+			return nil, fmt.Errorf("%q is not name:band", part)
+		}
+		if slices.ContainsFunc(tiers, func(t spendTier) bool { return t.name == name }) {
+			return nil, fmt.Errorf("tier %q is defined twice", name)
+		}
+		t, err := parseSpendBand(strings.TrimSpace(band))
+		if err != nil {
+			return nil, fmt.Errorf("tier %q: %v", name, err)
+		}
+		t.name = name
+		tiers = append(tiers, t)
+	}
+	return tiers, nil
+}
+
+// parseSpendBand parses "<N", "<=N", ">N", ">=N" or "A-B" (both ends
+// included).
+func parseSpendBand(band string) (spendTier, error) {
+	num := func(s string) (float64, error) {
+		v, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+		if err != nil || v < 0 {
+			return 0, fmt.Errorf("%q is not a non-negative amount", s)
+		}
+		return v, nil
+	}
+	var t spendTier
+	var err error
+	switch {
+	case strings.HasPrefix(band, "<="):
+		t.max, err = num(band[2:])
+		t.hasMax = true
+	case strings.HasPrefix(band, ">="):
+		t.min, err = num(band[2:])
+		t.hasMin = true
+	case strings.HasPrefix(band, "<"):
+		t.max, err = num(band[1:])
+		t.hasMax, t.maxOpen = true, true
+	case strings.HasPrefix(band, ">"):
+		t.min, err = num(band[1:])
+		t.hasMin, t.minOpen = true, true
+	default:
+		lo, hi, ok := strings.Cut(band, "-")
+		if !ok {
+			return t, fmt.Errorf("band %q must be <N, <=N, A-B, >=N, or >N", band)
+		}
+		if t.min, err = num(lo); err != nil {
+			return t, err
+		}
+		if t.max, err = num(hi); err != nil {
+			return t, err
+		}
+		if t.min > t.max {
+			return t, fmt.Errorf("band %q is empty", band)
+		}
+		t.hasMin, t.hasMax = true, true
+	}
+	return t, err
+}
+
+// spendTierOf returns the tier of the ad's estimated spend: the midpoint of
+// its range, converted with --convert-to when set. It is "" when tiers are
+// not in use, the ad has no spend, or no tier matches.
+func spendTierOf(a api.AdArchiveRecord) string {
+	if spendTiers == nil || a.Spend == nil {
+		return ""
+	}
+	spend := a.Spend
+	if convertToFlag != "" {
+		r, ok := convertedSpend(a)
+		if !ok {
+			return ""
+		}
+		spend = r
+	}
+	if _, ok := spend.Lower(); !ok {
+		return ""
+	}
+	mid := spend.Midpoint()
+	for _, t := range spendTiers {
+		if t.contains(mid) {
+			return t.name
+		}
+	}
+	return ""
+}
+
+// filterSpendTiers keeps the items whose spend tier is one of --spend-tier.
+func filterSpendTiers(items []json.RawMessage) []json.RawMessage {
+	if spendTiers != nil && convertToFlag == "" {
+		warnMixedTierCurrencies(items)
+	}
+	if len(spendTierFlag) == 0 {
+		return items
+	}
+	var kept []json.RawMessage
+	bad := 0
+	for _, item := range items {
+		var a api.AdArchiveRecord
+		if err := json.Unmarshal(item, &a); err != nil {
+			slog.Debug("skipping unparseable ad", "err", err, "record", bodySnippet(item))
+			bad++
+			continue
+		}
+		if slices.Contains(spendTierFlag, spendTierOf(a)) {
+			kept = append(kept, item)
+		}
+	}
+	warnUnparsed(bad)
+	return kept
+}
+
+// warnedMixedTiers is set once warnMixedTierCurrencies has warned.
+var warnedMixedTiers bool
+
+// warnMixedTierCurrencies warns when items spend in several currencies:
+// without --convert-to, tier bands apply to each ad's amount in its own
+// currency, so tiers aren't comparable across them.
+func warnMixedTierCurrencies(items []json.RawMessage) {
+	if warnedMixedTiers {
+		return
+	}
+	seen := map[string]bool{}
+	var currencies []string
+	for _, item := range items {
+		var rec struct {
+			Currency string `json:"currency"`
+		}
+		if json.Unmarshal(item, &rec) != nil || rec.Currency == "" || seen[rec.Currency] {
+			continue
+		}
+		seen[rec.Currency] = true
+		currencies = append(currencies, rec.Currency)
+	}
+	if len(currencies) > 1 {
+		warnedMixedTiers = true
+		sort.Strings(currencies)
+		slog.Warn(fmt.Sprintf("spend tiers compare amounts in different currencies (%s); add --convert-to to compare them in one", strings.Join(currencies, ", ")))
+	}
+}
@@ -0,0 +1,190 @@
+package cmd
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/the20100/meta-ad-library-cli/internal/api"
+)
+
+var (
+	validPlatforms  = []string{"facebook", "instagram", "audience_network", "messenger", "threads"}
+	validMediaTypes = []string{"ALL", "IMAGE", "MEME", "VIDEO", "NONE"}
+	validAdTypes    = []string{"ALL", "POLITICAL_AND_ISSUE_ADS"}
+	validStatuses   = []string{"ALL", "ACTIVE", "INACTIVE"}
+	validSorts      = []string{"spend", "-spend", "impressions", "-impressions"}
+)
+
+// checkChoice returns an error naming flag and the allowed set when value is not in allowed.
+func checkChoice(flag, value string, allowed []string) error {
+	for _, a := range allowed {
+		if value == a {
+			return nil
+		}
+	}
+	return usageErrorf("invalid --%s %q — allowed: %s", flag, value, strings.Join(allowed, ", "))
+}
+
+// normalizeUpper upper-cases value and checks it against allowed, so
+// "--status active" is accepted as ACTIVE.
+func normalizeUpper(flag, value string, allowed []string) (string, error) {
+	v := strings.ToUpper(strings.TrimSpace(value))
+	if err := checkChoice(flag, v, allowed); err != nil {
+		return "", err
+	}
+	return v, nil
+}
+
+// normalizeStatus resolves --status and its shorthands --active-only (ACTIVE)
+// and --include-inactive (ALL) to an ad_active_status value. A shorthand
+// contradicting an explicit --status, or both shorthands together, is a
+// usage error.
+func normalizeStatus(status string, activeOnly, includeInactive bool) (string, error) {
+	v, err := normalizeUpper("status", status, validStatuses)
+	if err != nil {
+		return "", err
+	}
+	if activeOnly && includeInactive {
+		return "", usageErrorf("--active-only and --include-inactive cannot be used together")
+	}
+	flag, want := "active-only", "ACTIVE"
+	switch {
+	case includeInactive:
+		flag, want = "include-inactive", "ALL"
+	case !activeOnly:
+		return v, nil
+	}
+	if v != "ALL" && v != want {
+		return "", usageErrorf("--%s contradicts --status %s", flag, v)
+	}
+	return want, nil
+}
+
+// normalizeLanguages lower-cases language codes and checks each is a two-letter
+// ISO 639-1 code, as Meta expects.
+func normalizeLanguages(langs []string) ([]string, error) {
+	out := make([]string, len(langs))
+	for i, l := range langs {
+		v := strings.ToLower(strings.TrimSpace(l))
+		if len(v) != 2 || v[0] < 'a' || v[0] > 'z' || v[1] < 'a' || v[1] > 'z' {
+			return nil, usageErrorf("invalid --language %q — expected a two-letter ISO 639-1 code (e.g. en, fr)", l)
+		}
+		out[i] = v
+	}
+	return out, nil
+}
+
+// isCountryCode reports whether v is two upper-case ASCII letters, the shape of
+// an ISO 3166-1 alpha-2 code.
+func isCountryCode(v string) bool {
+	return len(v) == 2 && v[0] >= 'A' && v[0] <= 'Z' && v[1] >= 'A' && v[1] <= 'Z'
+}
+
+// normalizeDate checks a --since/--until value and returns it as YYYY-MM-DD.
+// Besides absolute dates it accepts "today", "yesterday", and relative forms
+// counted back from today: "30d" (days) and "4w" (weeks).
+func normalizeDate(flag, value string) (string, error) {
+	if value == "" {
+		return "", nil
+	}
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	v := strings.ToLower(strings.TrimSpace(value))
+	switch v {
+	case "today":
+		return today.Format(time.DateOnly), nil
+	case "yesterday":
+		return today.AddDate(0, 0, -1).Format(time.DateOnly), nil
+	}
+	if n := len(v); n >= 2 && (v[n-1] == 'd' || v[n-1] == 'w') {
+		if count, err := strconv.Atoi(v[:n-1]); err == nil && count >= 0 {
+			days := count
+			if v[n-1] == 'w' {
+				days *= 7
+			}
+			return today.AddDate(0, 0, -days).Format(time.DateOnly), nil
+		}
+	}
+	if t, err := time.Parse(time.DateOnly, v); err == nil {
+		return t.Format(time.DateOnly), nil
+	}
+	return "", usageErrorf("invalid --%s %q — expected YYYY-MM-DD, today, yesterday, or a relative form like 30d or 4w", flag, value)
+}
+
+// normalizeDateRange normalizes --since and --until and checks they are in order.
+func normalizeDateRange(since, until string) (string, string, error) {
+	since, err := normalizeDate("since", since)
+	if err != nil {
+		return "", "", err
+	}
+	until, err = normalizeDate("until", until)
+	if err != nil {
+		return "", "", err
+	}
+	if since != "" && until != "" && since > until {
+		return "", "", usageErrorf("--since %s is after --until %s", since, until)
+	}
+	return since, until, nil
+}
+
+// noValidateFieldsFlag skips checkFields, for fields Meta added after this
+// release.
+var noValidateFieldsFlag bool
+
+// knownFields are the /ads_archive field names checkFields accepts.
+var knownFields = strings.Split(api.FieldsAll, ",")
+
+// checkFields rejects --fields entries that aren't known /ads_archive fields,
+// suggesting the closest known name. Sub-field selections such as
+// "spend{lower_bound}" are checked by their top-level name.
+func checkFields(fields string) error {
+	if noValidateFieldsFlag {
+		return nil
+	}
+	for _, f := range strings.Split(fields, ",") {
+		name, _, _ := strings.Cut(strings.TrimSpace(f), "{")
+		if name == "" || slices.Contains(knownFields, name) {
+			continue
+		}
+		if s := closestField(name); s != "" {
+			return usageErrorf("unknown field %q in --fields — did you mean %s? (use --no-validate-fields to send it anyway)", name, s)
+		}
+		return usageErrorf("unknown field %q in --fields (use --no-validate-fields to send it anyway)", name)
+	}
+	return nil
+}
+
+// closestField returns the known field nearest to name by edit distance, or ""
+// when none is close enough to be a plausible typo.
+func closestField(name string) string {
+	best, bestDist := "", max(3, len(name)/3)+1
+	for _, k := range knownFields {
+		if d := editDistance(name, k); d < bestDist {
+			best, bestDist = k, d
+		}
+	}
+	return best
+}
+
+// editDistance is the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,205 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/the20100/meta-ad-library-cli/internal/api"
+	"github.com/the20100/meta-ad-library-cli/internal/output"
+)
+
+const webhookAttempts = 3
+
+var (
+	watchInterval       time.Duration
+	watchLimit          int
+	watchWebhook        string
+	watchWebhookHeaders []string
+)
+
+var watchCmd = &cobra.Command{
+	Use:   "watch",
+	Short: "Poll a search and report newly detected ads",
+	Long: `Re-runs a search (same filters as the search command) every --interval and
+reports ads that weren't seen in earlier cycles. The first cycle only records
+a baseline of existing ads.
+
+With --webhook, each cycle's new ads are POSTed to the URL as a JSON array.
+Failed deliveries (network errors or non-2xx responses) are tried up to 3
+times in all, with backoff between attempts. Use --webhook-header for authentication headers.
+
+Stop with Ctrl-C.
+
+Examples:
+  meta-adlib watch --query "election" --country US --interval 15m
+  meta-adlib watch --page-id 123456789 --country DE --webhook https://hooks.example.com/ads
+  meta-adlib watch --query "shoes" --country FR --webhook https://example.com/in \
+    --webhook-header "Authorization: Bearer s3cret"`,
+	RunE: runWatch,
+}
+
+func init() {
+	addSearchFlags(watchCmd.Flags())
+	watchCmd.Flags().DurationVar(&watchInterval, "interval", 10*time.Minute, "Time between polls")
+	watchCmd.Flags().IntVar(&watchLimit, "limit", 100, "Maximum number of ads fetched per poll (0 = fetch all pages)")
+	watchCmd.Flags().StringVar(&watchWebhook, "webhook", "", "URL to POST each cycle's new ads to (JSON array)")
+	watchCmd.Flags().StringArrayVar(&watchWebhookHeaders, "webhook-header", nil, `Extra header for webhook requests ("Name: value"). Repeatable.`)
+
+	rootCmd.AddCommand(watchCmd)
+}
+
+func runWatch(cmd *cobra.Command, args []string) error {
+	if watchInterval <= 0 {
+		return usageErrorf("--interval must be positive")
+	}
+	headers, err := parseHeaders(watchWebhookHeaders)
+	if err != nil {
+		return err
+	}
+
+	params, err := searchOpts.params(api.FieldsSummary)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	seen := map[string]bool{}
+	baseline := true
+	for {
+		res, err := searchOpts.searchAds(params, watchLimit)
+		if err != nil {
+			slog.Warn("poll failed", "err", err)
+		} else {
+			fresh := newAds(res.items, seen)
+			switch {
+			case baseline:
+				slog.Info(fmt.Sprintf("watching: %d existing ad(s) recorded, polling every %s", len(fresh), watchInterval))
+				baseline = false
+			case len(fresh) > 0:
+				if err := reportNewAds(cmd, fresh); err != nil {
+					return err
+				}
+				if watchWebhook != "" {
+					if err := postWebhook(ctx, watchWebhook, headers, fresh); err != nil {
+						slog.Warn("webhook delivery failed", "err", err)
+					}
+				}
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(watchInterval):
+		}
+	}
+}
+
+// newAds returns the items whose IDs are not yet in seen, and marks them seen.
+// Items that can't be parsed are skipped with a warning, so one bad record
+// doesn't stop the watch.
+func newAds(items []json.RawMessage, seen map[string]bool) []json.RawMessage {
+	var fresh []json.RawMessage
+	bad := 0
+	for _, raw := range items {
+		var a struct {
+			ID string `json:"id"`
+		}
+		if err := json.Unmarshal(raw, &a); err != nil {
+			slog.Debug("skipping unparseable ad", "err", err, "record", bodySnippet(raw))
+			bad++
+			continue
+		}
+		if seen[a.ID] {
+			continue
+		}
+		seen[a.ID] = true
+		fresh = append(fresh, raw)
+	}
+	warnUnparsed(bad)
+	return fresh
+}
+
+func reportNewAds(cmd *cobra.Command, items []json.RawMessage) error {
+	if output.IsJSON(cmd) {
+		items, err := adsJSON(items)
+		if err != nil {
+			return err
+		}
+		return output.PrintJSON(items, output.IsPretty(cmd))
+	}
+
+	ads := parseAds(items)
+	fmt.Printf("\n%s — %d new ad(s)\n", time.Now().Format("2006-01-02 15:04"), len(ads))
+	printAdsTable(ads, nil)
+	return nil
+}
+
+// parseHeaders turns "Name: value" strings into an http.Header.
+func parseHeaders(raw []string) (http.Header, error) {
+	h := http.Header{}
+	for _, kv := range raw {
+		name, value, ok := strings.Cut(kv, ":")
+		if !ok || strings.TrimSpace(name) == "" {
+			return nil, usageErrorf("invalid header %q — expected \"Name: value\"", kv)
+		}
+		h.Add(strings.TrimSpace(name), strings.TrimSpace(value))
+	}
+	return h, nil
+}
+
+// postWebhook POSTs items as a JSON array to url, retrying on network errors
+// and non-2xx responses with a doubling backoff.
+func postWebhook(ctx context.Context, url string, headers http.Header, items []json.RawMessage) error {
+	body, err := json.Marshal(items)
+	if err != nil {
+		return err
+	}
+
+	httpClient := &http.Client{Timeout: 30 * time.Second}
+	backoff := 2 * time.Second
+	var lastErr error
+	for attempt := 1; attempt <= webhookAttempts; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		req.Header = headers.Clone()
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := httpClient.Do(req)
+		if err == nil {
+			io.Copy(io.Discard, resp.Body) //nolint:errcheck
+			resp.Body.Close()
+			if resp.StatusCode < 300 {
+				return nil
+			}
+			err = fmt.Errorf("HTTP %d", resp.StatusCode)
+		}
+		lastErr = err
+
+		if attempt == webhookAttempts {
+			break
+		}
+		slog.Info("retrying webhook", "attempt", attempt+1, "in", backoff, "err", err)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+	return fmt.Errorf("after %d attempts: %w", webhookAttempts, lastErr)
+}
@@ -0,0 +1,69 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// CanonicalJSON re-encodes a raw ad with a fixed key order, so the same ad
+// always encodes to the same bytes whatever order Meta sent its keys in.
+// The keys AdArchiveRecord knows come first, in struct order; any others
+// (fields the struct lacks, or keys added by the CLI) follow sorted by name.
+// Nested objects' keys are sorted too. Only the order changes: every key and
+// value is kept as sent, so nothing is dropped or added.
+func CanonicalJSON(raw json.RawMessage) (json.RawMessage, error) {
+	var all map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &all); err != nil {
+		return nil, fmt.Errorf("parsing ad: %w", err)
+	}
+	keys := make([]string, 0, len(all))
+	for _, k := range recordKeyOrder() {
+		if _, ok := all[k]; ok {
+			keys = append(keys, k)
+		}
+	}
+	known := recordKeys()
+	var rest []string
+	for k := range all {
+		if !known[k] {
+			rest = append(rest, k)
+		}
+	}
+	sort.Strings(rest)
+	keys = append(keys, rest...)
+
+	var b bytes.Buffer
+	b.WriteByte('{')
+	for i, k := range keys {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		key, err := json.Marshal(k)
+		if err != nil {
+			return nil, err
+		}
+		val, err := sortedKeys(all[k])
+		if err != nil {
+			return nil, err
+		}
+		b.Write(key)
+		b.WriteByte(':')
+		b.Write(val)
+	}
+	b.WriteByte('}')
+	return b.Bytes(), nil
+}
+
+// sortedKeys re-encodes a JSON value compactly with object keys sorted.
+// Numbers are kept as written.
+func sortedKeys(raw json.RawMessage) (json.RawMessage, error) {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	return json.Marshal(v)
+}
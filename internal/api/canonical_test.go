@@ -0,0 +1,74 @@
+package api
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestCanonicalJSON(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{
+			name: "modeled keys in struct order",
+			in:   `{"page_name":"P","spend":{"upper_bound":"99","lower_bound":"0"},"id":"1","currency":"EUR"}`,
+			want: `{"id":"1","currency":"EUR","spend":{"lower_bound":"0","upper_bound":"99"},"page_name":"P"}`,
+		},
+		{
+			name: "open-ended range keeps only the bound sent",
+			in:   `{"impressions":{"lower_bound":"1000000"}}`,
+			want: `{"impressions":{"lower_bound":"1000000"}}`,
+		},
+		{
+			name: "unmodeled keys follow, sorted",
+			in:   `{"zeta":1,"id":"1","alpha":{"b":2,"a":1}}`,
+			want: `{"id":"1","alpha":{"a":1,"b":2},"zeta":1}`,
+		},
+		{
+			name: "unmodeled nested keys are kept",
+			in:   `{"target_locations":[{"name":"Paris","excluded":false,"type":"city","new_key":"x"}]}`,
+			want: `{"target_locations":[{"excluded":false,"name":"Paris","new_key":"x","type":"city"}]}`,
+		},
+		{
+			name: "values of unexpected type are kept",
+			in:   `{"id":123,"eu_total_reach":"about 5000","spend":null}`,
+			want: `{"id":123,"spend":null,"eu_total_reach":"about 5000"}`,
+		},
+		{
+			name: "numbers as written",
+			in:   `{"x":1.50,"y":10000000000000000001}`,
+			want: `{"x":1.50,"y":10000000000000000001}`,
+		},
+		{
+			name: "empty object",
+			in:   `{}`,
+			want: `{}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := CanonicalJSON(json.RawMessage(tt.in))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("CanonicalJSON(%s)\n got %s\nwant %s", tt.in, got, tt.want)
+			}
+			again, err := CanonicalJSON(got)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(again) != string(got) {
+				t.Errorf("not idempotent: %s became %s", got, again)
+			}
+		})
+	}
+}
+
+func TestCanonicalJSONNotAnObject(t *testing.T) {
+	if _, err := CanonicalJSON(json.RawMessage(`[1]`)); err == nil {
+		t.Error("CanonicalJSON of an array succeeded; want error")
+	}
+}
@@ -1,35 +1,179 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"log/slog"
+	"math/rand/v2"
 	"net/http"
 	"net/url"
-	"os"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
+
+	"github.com/the20100/meta-ad-library-cli/internal/logging"
 )
 
 const (
 	baseURL    = "https://graph.facebook.com/v23.0"
 	adLibPath  = "/ads_archive"
+	pagesPath  = "/pages/search"
 )
 
+// DefaultRateWarnAt is the X-App-Usage percentage above which a warning is printed.
+const DefaultRateWarnAt = 75
+
+// DefaultMaxEmptyPages is how many consecutive empty pages SearchAds follows
+// before giving up on a paging.next cursor.
+const DefaultMaxEmptyPages = 3
+
 // Client is an authenticated Meta Graph API client.
 type Client struct {
-	token      string
-	httpClient *http.Client
+	token         string
+	httpClient    *http.Client
+	rateWarnAt    int
+	noRateWarn    bool
+	retry         RetryConfig
+	maxEmptyPages int
+	userAgent     string
+	ctx           context.Context
+	observer      Observer
+
+	// usageMu guards usage, which bulk fetches update from several goroutines.
+	usageMu sync.Mutex
+	usage   Usage
+}
+
+// Usage is the API footprint of a client so far.
+type Usage struct {
+	// Calls counts HTTP requests made, retries included.
+	Calls int
+	// AppUsage is the last X-App-Usage percentage seen (the higher of
+	// call_count and total_time), valid when HasAppUsage is true.
+	AppUsage    int
+	HasAppUsage bool
+}
+
+// Usage returns the number of requests made so far and the last app usage
+// Meta reported.
+func (c *Client) Usage() Usage {
+	c.usageMu.Lock()
+	defer c.usageMu.Unlock()
+	return c.usage
+}
+
+// Observer is told about every HTTP request the client makes, retries
+// included, for metrics.
+type Observer interface {
+	// RequestDone is called after each attempt with how long it took and
+	// the error it failed with, if any.
+	RequestDone(took time.Duration, err error)
+	// RateLimitWarned is called when API usage crosses the WithRateWarnAt
+	// threshold, whether or not the warning is printed.
+	RateLimitWarned()
+}
+
+// Option configures a Client.
+type Option func(*Client)
+
+// WithRateWarnAt sets the API usage percentage above which a rate-limit
+// warning is printed to stderr.
+func WithRateWarnAt(pct int) Option {
+	return func(c *Client) {
+		c.rateWarnAt = pct
+	}
+}
+
+// WithRateWarnings turns the rate-limit warning on or off. Other warnings are
+// unaffected.
+func WithRateWarnings(enabled bool) Option {
+	return func(c *Client) {
+		c.noRateWarn = !enabled
+	}
+}
+
+// WithMaxEmptyPages sets how many consecutive pages with no data SearchAds
+// tolerates while paging.next is still present.
+func WithMaxEmptyPages(n int) Option {
+	return func(c *Client) {
+		c.maxEmptyPages = n
+	}
+}
+
+// WithUserAgent sets the User-Agent header sent with every request.
+func WithUserAgent(ua string) Option {
+	return func(c *Client) {
+		c.userAgent = ua
+	}
+}
+
+// WithContext makes every request use ctx. Once ctx is done, requests fail
+// and Search returns the results fetched so far; this bounds a whole
+// multi-page operation, where the HTTP timeout only bounds each request.
+func WithContext(ctx context.Context) Option {
+	return func(c *Client) {
+		c.ctx = ctx
+	}
+}
+
+// WithObserver reports every request to o.
+func WithObserver(o Observer) Option {
+	return func(c *Client) {
+		c.observer = o
+	}
 }
 
 // NewClient creates a new Client.
-func NewClient(token string) *Client {
-	return &Client{
+func NewClient(token string, opts ...Option) *Client {
+	c := &Client{
 		token: token,
 		httpClient: &http.Client{
 			Timeout: 60 * time.Second,
 		},
+		rateWarnAt:    DefaultRateWarnAt,
+		maxEmptyPages: DefaultMaxEmptyPages,
+		ctx:           context.Background(),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// RateWarnAt returns the usage percentage above which the client warns.
+func (c *Client) RateWarnAt() int {
+	return c.rateWarnAt
+}
+
+// Context returns the context requests are made with (see WithContext).
+func (c *Client) Context() context.Context {
+	return c.ctx
+}
+
+// Token returns the access token the client authenticates with.
+func (c *Client) Token() string {
+	return c.token
+}
+
+// SnapshotURL returns an ad_snapshot_url with the client's access token set.
+// Meta's snapshot page refuses to render many ads without it. An empty raw
+// URL is returned unchanged.
+func (c *Client) SnapshotURL(raw string) (string, error) {
+	if raw == "" {
+		return "", nil
 	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "", fmt.Errorf("parsing snapshot URL: %w", err)
+	}
+	q := u.Query()
+	q.Set("access_token", c.token)
+	u.RawQuery = q.Encode()
+	return u.String(), nil
 }
 
 // baseParams returns common query parameters added to every request.
@@ -39,8 +183,9 @@ func (c *Client) baseParams() url.Values {
 	return params
 }
 
-// checkRateLimit reads X-App-Usage and warns to stderr if high.
-func checkRateLimit(headers http.Header) {
+// checkRateLimit reads X-App-Usage and warns to stderr if usage exceeds the
+// client's threshold.
+func (c *Client) checkRateLimit(headers http.Header) {
 	usage := headers.Get("X-App-Usage")
 	if usage == "" {
 		return
@@ -57,40 +202,99 @@ func checkRateLimit(headers http.Header) {
 	if parsed.TotalTime > pct {
 		pct = parsed.TotalTime
 	}
-	if pct > 75 {
-		fmt.Fprintf(os.Stderr, "warning: rate limit %d%% used — slow down to avoid HTTP 613\n", pct)
+	c.usageMu.Lock()
+	c.usage.AppUsage, c.usage.HasAppUsage = pct, true
+	c.usageMu.Unlock()
+	if pct <= c.rateWarnAt {
+		return
+	}
+	if c.observer != nil {
+		c.observer.RateLimitWarned()
+	}
+	if !c.noRateWarn {
+		slog.Warn(fmt.Sprintf("rate limit %d%% used — slow down to avoid HTTP 613", pct))
 	}
 }
 
-// doRequest executes an HTTP request and returns the body bytes.
+// doRequest executes an HTTP request and returns the body bytes, retrying as
+// configured with WithRetry. req must not have a body.
 func (c *Client) doRequest(req *http.Request) ([]byte, error) {
+	return c.retry.withRetry(c.ctx, func() ([]byte, error) {
+		start := time.Now()
+		body, err := c.doOnce(req)
+		if c.observer != nil {
+			c.observer.RequestDone(time.Since(start), err)
+		}
+		return body, err
+	})
+}
+
+// doOnce executes an HTTP request once.
+func (c *Client) doOnce(req *http.Request) ([]byte, error) {
+	c.usageMu.Lock()
+	c.usage.Calls++
+	c.usageMu.Unlock()
+	start := time.Now()
+	slog.Debug(req.Method, "url", logging.RedactURL(req.URL.String()))
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
+		// Transport errors quote the URL, token included.
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			urlErr.URL = logging.RedactURL(urlErr.URL)
+		}
 		return nil, fmt.Errorf("request failed: %w", err)
 	}
 	defer resp.Body.Close()
+	slog.Debug("response", "status", resp.StatusCode, "took", time.Since(start).Round(time.Millisecond),
+		"app_usage", resp.Header.Get("X-App-Usage"))
 
-	checkRateLimit(resp.Header)
+	c.checkRateLimit(resp.Header)
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
 		return nil, fmt.Errorf("reading response: %w", err)
 	}
 
+	// Outages often come back as HTML pages; report the status instead of a
+	// confusing JSON parse error further up.
+	if !json.Valid(body) {
+		if resp.StatusCode >= 400 {
+			return nil, &HTTPError{resp.StatusCode, "server returned non-JSON (possibly an outage): " + bodySnippet(body)}
+		}
+		return nil, &HTTPError{resp.StatusCode, "unexpected non-JSON response: " + bodySnippet(body)}
+	}
+
 	var errResp struct {
 		Error *MetaError `json:"error"`
 	}
 	if err := json.Unmarshal(body, &errResp); err == nil && errResp.Error != nil {
+		if errResp.Error.IsAdLibraryAccess() {
+			return nil, &AdLibraryAccessError{Err: errResp.Error}
+		}
 		return nil, errResp.Error
 	}
 
 	if resp.StatusCode >= 400 {
-		return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, string(body))
+		return nil, &HTTPError{resp.StatusCode, string(body)}
 	}
 
 	return body, nil
 }
 
+// bodySnippet returns the start of body with whitespace collapsed, for error messages.
+func bodySnippet(body []byte) string {
+	const maxLen = 200
+	s := strings.Join(strings.Fields(string(body)), " ")
+	if s == "" {
+		return "(empty body)"
+	}
+	if r := []rune(s); len(r) > maxLen {
+		return string(r[:maxLen]) + "…"
+	}
+	return s
+}
+
 // Get makes an authenticated GET request.
 func (c *Client) Get(path string, params url.Values) ([]byte, error) {
 	reqURL, err := buildURL(path, c.baseParams(), params)
@@ -98,19 +302,61 @@ func (c *Client) Get(path string, params url.Values) ([]byte, error) {
 		return nil, err
 	}
 
-	req, err := http.NewRequest(http.MethodGet, reqURL, nil)
+	req, err := http.NewRequestWithContext(c.ctx, http.MethodGet, reqURL, nil)
 	if err != nil {
 		return nil, err
 	}
 	req.Header.Set("Accept", "application/json")
+	if c.userAgent != "" {
+		req.Header.Set("User-Agent", c.userAgent)
+	}
 	return c.doRequest(req)
 }
 
-// SearchAds queries the /ads_archive endpoint with the given params.
-// It follows paging.next cursors and returns all results up to limit (0 = all).
-func (c *Client) SearchAds(params url.Values, limit int) ([]json.RawMessage, error) {
-	var all []json.RawMessage
+// RequestURL returns the URL Get would request for path and params, with the
+// access token redacted. No request is made.
+func (c *Client) RequestURL(path string, params url.Values) (string, error) {
+	base := c.baseParams()
+	base.Set("access_token", "REDACTED")
+	return buildURL(path, base, params)
+}
+
+// SearchURL returns the URL of the first /ads_archive page Search would
+// request for params and opts, with the access token redacted.
+func (c *Client) SearchURL(params url.Values, opts SearchOptions) (string, error) {
+	return c.RequestURL(adLibPath, searchParams(params, opts))
+}
+
+// defaultPageSize is the per-page batch size when the caller sets none.
+const defaultPageSize = 100
+
+// maxPageSize is the largest page /ads_archive serves.
+const maxPageSize = 2000
+
+// PagesNeeded returns how many requests a search needs to reach opts.Limit,
+// given the page size it will ask for, or 0 when there is no limit. Fewer are
+// made when the results run out first.
+func PagesNeeded(params url.Values, opts SearchOptions) int {
+	if opts.Limit <= 0 || opts.FirstPageOnly {
+		return 0
+	}
+	size, err := strconv.Atoi(searchParams(params, opts).Get("limit"))
+	if err != nil || size <= 0 {
+		return 0
+	}
+	pages := (opts.Limit + size - 1) / size
+	if opts.MaxPages > 0 && pages > opts.MaxPages {
+		pages = opts.MaxPages
+	}
+	return pages
+}
 
+// searchParams clones params and fills in the page size: defaultPageSize, or
+// limit when that is smaller, so small searches don't over-fetch. A
+// first-page-only search asks for up to maxPageSize instead, since it gets a
+// single page.
+func searchParams(params url.Values, opts SearchOptions) url.Values {
+	limit := opts.Limit
 	// Clone to avoid mutating caller's map
 	p := url.Values{}
 	for k, v := range params {
@@ -119,13 +365,131 @@ func (c *Client) SearchAds(params url.Values, limit int) ([]json.RawMessage, err
 
 	// API max per page is 2000; use 100 as default batch size
 	if p.Get("limit") == "" {
-		p.Set("limit", "100")
+		size := defaultPageSize
+		if opts.FirstPageOnly {
+			size = maxPageSize
+		}
+		if limit > 0 && limit < size {
+			size = limit
+		}
+		p.Set("limit", strconv.Itoa(size))
+	}
+	return p
+}
+
+// GetAd fetches a single ad by archive ID, requesting fields (the API's default
+// set when empty). Any extra params are sent verbatim and win over fields. It
+// returns both the decoded record and the raw response body.
+func (c *Client) GetAd(id string, fields []string, extra ...url.Values) (*AdArchiveRecord, []byte, error) {
+	params := url.Values{}
+	if len(fields) > 0 {
+		params.Set("fields", strings.Join(fields, ","))
+	}
+	for _, e := range extra {
+		for k, vs := range e {
+			params[k] = vs
+		}
 	}
 
+	body, err := c.Get("/"+id, params)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var a AdArchiveRecord
+	if err := json.Unmarshal(body, &a); err != nil {
+		return nil, nil, fmt.Errorf("parsing ad: %w", err)
+	}
+	return &a, body, nil
+}
+
+// SearchOptions controls how Search pages through /ads_archive.
+type SearchOptions struct {
+	// Limit caps the number of results (0 = all).
+	Limit int
+	// FirstPageOnly makes exactly one request and ignores paging.next.
+	FirstPageOnly bool
+	// MaxPages stops paging after this many requests (0 = no cap).
+	MaxPages int
+	// Sample, when positive, keeps a uniform random sample of this many
+	// results out of everything fetched, using reservoir sampling so memory
+	// stays bounded. Limit is ignored. Rand is the randomness source; nil
+	// means a randomly seeded one.
+	Sample int
+	Rand   *rand.Rand
+	// SampleFilter, when set with Sample, drops results from each page
+	// before they are sampled, so the sample is drawn only from results the
+	// caller keeps.
+	SampleFilter func(items []json.RawMessage) []json.RawMessage
+	// After resumes paging from this cursor, as passed to OnPage by an
+	// earlier search with the same params.
+	After string
+	// OnPage, when set, receives each page's results (cut to Limit) instead
+	// of Search collecting them, along with the cursor to pass as After to
+	// resume after that page ("" on the last page). An error stops the
+	// search and is returned. Sample is ignored.
+	OnPage func(items []json.RawMessage, after string) error
+}
+
+// SearchResult is the outcome of a paged /ads_archive search.
+type SearchResult struct {
+	Items []json.RawMessage
+	// Truncated is true when Limit or MaxPages was reached while more results
+	// remained.
+	Truncated bool
+	// MaxPagesReached is true when paging stopped because of MaxPages.
+	MaxPagesReached bool
+	// DeadlineReached is true when the client's context (see WithContext)
+	// expired after at least one page, so Items is partial.
+	DeadlineReached bool
+}
+
+// SearchAds queries the /ads_archive endpoint with the given params.
+// It follows paging.next cursors and returns all results up to limit (0 = all).
+func (c *Client) SearchAds(params url.Values, limit int) ([]json.RawMessage, error) {
+	res, err := c.Search(params, SearchOptions{Limit: limit})
+	if err != nil {
+		return nil, err
+	}
+	return res.Items, nil
+}
+
+// Search is SearchAds with options and details about how paging ended.
+func (c *Client) Search(params url.Values, opts SearchOptions) (*SearchResult, error) {
+	var all []json.RawMessage
+	limit := opts.Limit
+	truncated := false
+
+	p := searchParams(params, opts)
+	if opts.After != "" {
+		p.Set("after", opts.After)
+	}
 	currentPath := adLibPath
+	emptyPages := 0
+	pages := 0
+	seen := 0
+	fetched := 0
+	rng := opts.Rand
+	if opts.OnPage != nil {
+		opts.Sample = 0
+	}
+	if opts.Sample > 0 {
+		limit = 0
+		if rng == nil {
+			rng = rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64()))
+		}
+	}
 
 	for {
 		body, err := c.Get(currentPath, p)
+		var metaErr *MetaError
+		if (currentPath != adLibPath || opts.After != "") && errors.As(err, &metaErr) && metaErr.IsCursorExpired() {
+			return nil, &CursorExpiredError{Fetched: fetched, Err: metaErr}
+		}
+		if err != nil && pages > 0 && errors.Is(c.ctx.Err(), context.DeadlineExceeded) {
+			slog.Warn(fmt.Sprintf("deadline reached after %d page(s) — results are incomplete", pages))
+			return &SearchResult{Items: shuffled(all, rng), DeadlineReached: true}, nil
+		}
 		if err != nil {
 			return nil, err
 		}
@@ -138,24 +502,133 @@ func (c *Client) SearchAds(params url.Values, limit int) ([]json.RawMessage, err
 			return nil, fmt.Errorf("parsing page: %w", err)
 		}
 
-		all = append(all, page.Data...)
+		pages++
+		hasNext := page.Paging != nil && page.Paging.Next != ""
+		data := page.Data
+		if limit > 0 && fetched+len(data) > limit {
+			data = data[:limit-fetched]
+			truncated = true
+		}
+		fetched += len(data)
+		switch {
+		case opts.OnPage != nil:
+			after := ""
+			if hasNext && page.Paging.Cursors != nil {
+				after = page.Paging.Cursors.After
+			}
+			if err := opts.OnPage(data, after); err != nil {
+				return nil, err
+			}
+		case opts.Sample > 0:
+			if opts.SampleFilter != nil {
+				data = opts.SampleFilter(data)
+			}
+			for _, item := range data {
+				seen++
+				if len(all) < opts.Sample {
+					all = append(all, item)
+				} else if j := rng.IntN(seen); j < opts.Sample {
+					all[j] = item
+				}
+			}
+		default:
+			all = append(all, data...)
+		}
+
+		// Guard against a degenerate cursor that keeps returning nothing.
+		if len(page.Data) == 0 {
+			emptyPages++
+			if emptyPages >= c.maxEmptyPages && hasNext {
+				slog.Warn(fmt.Sprintf("stopped paging after %d empty page(s) — results may be incomplete", emptyPages))
+				break
+			}
+		} else {
+			emptyPages = 0
+		}
 
 		// Enforce caller's limit
-		if limit > 0 && len(all) >= limit {
-			all = all[:limit]
+		if limit > 0 && fetched >= limit {
+			truncated = truncated || hasNext
 			break
 		}
 
-		if page.Paging == nil || page.Paging.Next == "" {
+		if !hasNext {
+			break
+		}
+		if opts.FirstPageOnly {
+			truncated = true
 			break
 		}
+		if opts.MaxPages > 0 && pages >= opts.MaxPages {
+			return &SearchResult{Items: shuffled(all, rng), Truncated: true, MaxPagesReached: true}, nil
+		}
 
 		// Next page URL already contains all params
 		currentPath = page.Paging.Next
 		p = url.Values{}
 	}
 
-	return all, nil
+	return &SearchResult{Items: shuffled(all, rng), Truncated: truncated}, nil
+}
+
+// shuffled shuffles a reservoir sample in place, since the reservoir keeps
+// early results in fetch order. It returns items unchanged when rng is nil
+// (no sampling).
+func shuffled(items []json.RawMessage, rng *rand.Rand) []json.RawMessage {
+	if rng != nil {
+		rng.Shuffle(len(items), func(i, j int) { items[i], items[j] = items[j], items[i] })
+	}
+	return items
+}
+
+// Count asks /ads_archive for the number of ads matching params with a
+// single request (summary=total_count). ok is false when the response carries
+// no total, in which case the caller has to page through the results.
+func (c *Client) Count(params url.Values) (total int, ok bool, err error) {
+	p := url.Values{}
+	for k, v := range params {
+		p[k] = v
+	}
+	p.Set("summary", "total_count")
+	p.Set("limit", "1")
+
+	body, err := c.Get(adLibPath, p)
+	if err != nil {
+		return 0, false, err
+	}
+	var resp struct {
+		Summary *struct {
+			TotalCount *int `json:"total_count"`
+		} `json:"summary"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return 0, false, fmt.Errorf("parsing response: %w", err)
+	}
+	if resp.Summary == nil || resp.Summary.TotalCount == nil {
+		return 0, false, nil
+	}
+	return *resp.Summary.TotalCount, true, nil
+}
+
+// SearchPages looks up Facebook Pages by name via /pages/search.
+// This endpoint requires the Page Public Metadata Access feature on the app.
+func (c *Client) SearchPages(query string) ([]Page, error) {
+	params := url.Values{}
+	params.Set("q", query)
+	params.Set("fields", "id,name,category,verification_status,link")
+
+	body, err := c.Get(pagesPath, params)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp struct {
+		Data []Page `json:"data"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("parsing pages: %w", err)
+	}
+	return resp.Data, nil
 }
 
 // buildURL constructs a full URL from path, base params, and extra params.
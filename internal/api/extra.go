@@ -0,0 +1,100 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// plainRecord has AdArchiveRecord's fields without its JSON methods, so they
+// can use the default encoding without recursing.
+type plainRecord AdArchiveRecord
+
+// recordKeyOrder returns the JSON keys AdArchiveRecord models, in field order.
+var recordKeyOrder = sync.OnceValue(func() []string {
+	var keys []string
+	t := reflect.TypeOf(AdArchiveRecord{})
+	for i := range t.NumField() {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			keys = append(keys, name)
+		}
+	}
+	return keys
+})
+
+// recordKeys returns the JSON keys AdArchiveRecord models.
+var recordKeys = sync.OnceValue(func() map[string]bool {
+	keys := map[string]bool{}
+	for _, k := range recordKeyOrder() {
+		keys[k] = true
+	}
+	return keys
+})
+
+// UnmarshalJSON decodes the modeled fields as usual and keeps every other key
+// in Extra, so fields Meta adds later aren't lost.
+func (a *AdArchiveRecord) UnmarshalJSON(data []byte) error {
+	var p plainRecord
+	if err := json.Unmarshal(data, &p); err != nil {
+		return err
+	}
+	var all map[string]json.RawMessage
+	if err := json.Unmarshal(data, &all); err != nil {
+		return err
+	}
+	for k := range all {
+		if recordKeys()[k] {
+			delete(all, k)
+		}
+	}
+	*a = AdArchiveRecord(p)
+	if len(all) > 0 {
+		extra, err := json.Marshal(all)
+		if err != nil {
+			return err
+		}
+		a.Extra = extra
+	}
+	return nil
+}
+
+// MarshalJSON encodes the modeled fields, then the keys in Extra sorted by
+// name. Keys in Extra that the struct models are skipped.
+func (a AdArchiveRecord) MarshalJSON() ([]byte, error) {
+	known, err := json.Marshal(plainRecord(a))
+	if err != nil {
+		return nil, err
+	}
+	if len(a.Extra) == 0 {
+		return known, nil
+	}
+	var extra map[string]json.RawMessage
+	if err := json.Unmarshal(a.Extra, &extra); err != nil {
+		return nil, err
+	}
+	keys := make([]string, 0, len(extra))
+	for k := range extra {
+		if !recordKeys()[k] {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	var b bytes.Buffer
+	b.Write(known[:len(known)-1]) // without the closing brace
+	for _, k := range keys {
+		if b.Len() > 1 {
+			b.WriteByte(',')
+		}
+		key, _ := json.Marshal(k)
+		b.Write(key)
+		b.WriteByte(':')
+		b.Write(extra[k])
+	}
+	b.WriteByte('}')
+	return b.Bytes(), nil
+}
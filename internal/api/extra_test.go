@@ -0,0 +1,74 @@
+package api
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestAdArchiveRecordRoundTrip(t *testing.T) {
+	tests := []struct {
+		name      string
+		in        string
+		wantExtra string
+		want      string
+	}{
+		{
+			name:      "unknown keys kept",
+			in:        `{"id":"1","new_field":{"b":1,"a":2},"another":[1]}`,
+			wantExtra: `{"another":[1],"new_field":{"b":1,"a":2}}`,
+			want:      `{"id":"1","another":[1],"new_field":{"b":1,"a":2}}`,
+		},
+		{
+			name: "no unknown keys",
+			in:   `{"id":"1","page_name":"P"}`,
+			want: `{"id":"1","page_name":"P"}`,
+		},
+		{
+			name: "open-ended range",
+			in:   `{"id":"1","impressions":{"lower_bound":"1000000"}}`,
+			want: `{"id":"1","impressions":{"lower_bound":"1000000"}}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var a AdArchiveRecord
+			if err := json.Unmarshal([]byte(tt.in), &a); err != nil {
+				t.Fatal(err)
+			}
+			if string(a.Extra) != tt.wantExtra {
+				t.Errorf("Extra = %s; want %s", a.Extra, tt.wantExtra)
+			}
+			got, err := json.Marshal(a)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("Marshal = %s; want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAdArchiveRecordExtraCollision(t *testing.T) {
+	a := AdArchiveRecord{ID: "1", PageName: "P", Extra: json.RawMessage(`{"page_name":"stale","id":"2","x":true}`)}
+	got, err := json.Marshal(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"id":"1","page_name":"P","x":true}`; string(got) != want {
+		t.Errorf("Marshal = %s; want %s", got, want)
+	}
+}
+
+func TestAdArchiveRecordEmptyExtra(t *testing.T) {
+	for _, extra := range []json.RawMessage{nil, json.RawMessage(`{}`)} {
+		a := AdArchiveRecord{ID: "1", Extra: extra}
+		got, err := json.Marshal(a)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := `{"id":"1"}`; string(got) != want {
+			t.Errorf("Marshal with Extra %q = %s; want %s", extra, got, want)
+		}
+	}
+}
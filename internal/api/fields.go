@@ -0,0 +1,27 @@
+package api
+
+// Field sets for /ads_archive, as comma-separated lists ready for the fields
+// param. Each set extends the previous one, so a field added to a smaller set
+// reaches every command using a larger one. funding_entity is left out: it
+// has been deprecated since v13.
+const (
+	// FieldsSummary is what list views request: search, page ads, watch
+	// and serve's /search.
+	FieldsSummary = "id,ad_creation_time,ad_delivery_start_time,ad_delivery_stop_time," +
+		"ad_creative_bodies,ad_creative_link_titles,ad_creative_link_captions," +
+		"ad_snapshot_url,page_id,page_name,publisher_platforms,languages," +
+		"spend,impressions,currency"
+
+	// FieldsDetail adds creative and targeting details, for views of one ad
+	// at a time and for exports: ad get, browse, export and serve's /ad.
+	FieldsDetail = FieldsSummary + "," +
+		"ad_creative_image_urls,ad_creative_link_descriptions,bylines," +
+		"region_distribution,demographic_distribution," +
+		"target_ages,target_gender,target_locations"
+
+	// FieldsAll is every documented field, requested by --all-fields.
+	FieldsAll = FieldsDetail + "," +
+		"estimated_audience_size,delivery_by_region," +
+		"age_country_gender_reach_breakdown,beneficiary_payers," +
+		"eu_total_reach,br_total_reach"
+)
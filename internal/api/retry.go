@@ -0,0 +1,87 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/url"
+	"time"
+)
+
+// RetryConfig controls how the client retries failed requests. The zero value
+// disables retries.
+type RetryConfig struct {
+	// MaxAttempts is the total number of tries per request, the first one
+	// included. Values below 2 disable retries.
+	MaxAttempts int
+	// BaseDelay is the wait before the first retry; it doubles on each retry.
+	BaseDelay time.Duration
+	// MaxDelay caps the wait between attempts (0 = no cap).
+	MaxDelay time.Duration
+	// Retryable decides whether an error is worth retrying. nil means
+	// DefaultRetryable.
+	Retryable func(err error) bool
+}
+
+// WithRetry sets the retry policy for every request the client makes.
+func WithRetry(rc RetryConfig) Option {
+	return func(c *Client) {
+		c.retry = rc
+	}
+}
+
+// DefaultRetryable retries network failures, HTTP 429 and 5xx responses, and
+// Meta errors that are rate limits or flagged as transient. Requests stopped
+// by their context are not retried.
+func DefaultRetryable(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var metaErr *MetaError
+	if errors.As(err, &metaErr) {
+		return metaErr.IsRateLimit() || metaErr.IsTransient()
+	}
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.StatusCode == 429 || httpErr.StatusCode >= 500
+	}
+	var urlErr *url.Error
+	return errors.As(err, &urlErr)
+}
+
+// delay returns the wait before retry number n (1-based).
+func (rc RetryConfig) delay(n int) time.Duration {
+	d := rc.BaseDelay
+	for i := 1; i < n; i++ {
+		d *= 2
+		if rc.MaxDelay > 0 && d >= rc.MaxDelay {
+			break
+		}
+	}
+	if rc.MaxDelay > 0 && d > rc.MaxDelay {
+		d = rc.MaxDelay
+	}
+	return d
+}
+
+// withRetry runs do until it succeeds, fails with an error the policy doesn't
+// retry, runs out of attempts, or ctx is done.
+func (rc RetryConfig) withRetry(ctx context.Context, do func() ([]byte, error)) ([]byte, error) {
+	retryable := rc.Retryable
+	if retryable == nil {
+		retryable = DefaultRetryable
+	}
+	for attempt := 1; ; attempt++ {
+		body, err := do()
+		if err == nil || attempt >= rc.MaxAttempts || !retryable(err) {
+			return body, err
+		}
+		d := rc.delay(attempt)
+		slog.Info("retrying request", "attempt", attempt+1, "in", d, "err", err)
+		select {
+		case <-time.After(d):
+		case <-ctx.Done():
+			return body, err
+		}
+	}
+}
@@ -1,6 +1,11 @@
 package api
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"net/url"
+	"strconv"
+	"strings"
+)
 
 // MetaError wraps a Meta API error response.
 type MetaError struct {
@@ -8,6 +13,8 @@ type MetaError struct {
 	Message string `json:"message"`
 	Type    string `json:"type"`
 	Subcode int    `json:"error_subcode"`
+	// Transient is set by Meta on errors that may succeed if retried.
+	Transient bool `json:"is_transient"`
 }
 
 func (e *MetaError) Error() string {
@@ -17,6 +24,97 @@ func (e *MetaError) Error() string {
 	return "meta api error " + itoa(e.Code) + ": " + e.Message
 }
 
+// IsRateLimit reports whether Meta throttled the request
+// (4: app, 17: user, 613: custom rate limit).
+func (e *MetaError) IsRateLimit() bool {
+	switch e.Code {
+	case 4, 17, 613:
+		return true
+	}
+	return false
+}
+
+// IsTransient reports whether the error is temporary: flagged is_transient,
+// or code 1 (unknown error) or 2 (service temporarily unavailable).
+func (e *MetaError) IsTransient() bool {
+	return e.Transient || e.Code == 1 || e.Code == 2
+}
+
+// IsAuth reports whether the token was rejected (190: invalid/expired token,
+// 102: session error).
+func (e *MetaError) IsAuth() bool {
+	return e.Code == 190 || e.Code == 102
+}
+
+// IsAdLibraryAccess reports whether the token is valid but not allowed to use
+// the Ad Library API (code 10, subcode 2332002 or 2332004, or a message about
+// the Ad Library or identity confirmation).
+func (e *MetaError) IsAdLibraryAccess() bool {
+	if e.Code != 10 {
+		return false
+	}
+	if e.Subcode == 2332002 || e.Subcode == 2332004 {
+		return true
+	}
+	msg := strings.ToLower(e.Message)
+	return strings.Contains(msg, "ad library") || strings.Contains(msg, "ads library") || strings.Contains(msg, "confirm your identity")
+}
+
+// IsCursorExpired reports whether Meta rejected a paging cursor, which happens
+// when a long pagination outlives the cursor (code 100, "invalid cursor" or
+// "expired" in the message; subcode 1357046 on some API versions).
+func (e *MetaError) IsCursorExpired() bool {
+	if e.Code != 100 {
+		return false
+	}
+	msg := strings.ToLower(e.Message)
+	return e.Subcode == 1357046 || (strings.Contains(msg, "cursor") && (strings.Contains(msg, "invalid") || strings.Contains(msg, "expired")))
+}
+
+// AdLibraryAccessError is returned when the token works but lacks Ad Library
+// API access. Its message says how to get access.
+type AdLibraryAccessError struct {
+	Err *MetaError
+}
+
+// AdLibraryAccessHint is the next step for an AdLibraryAccessError.
+const AdLibraryAccessHint = "confirm your identity and location at https://www.facebook.com/ID, " +
+	"then accept the Ad Library API terms at https://www.facebook.com/ads/library/api " +
+	"and use a token from an app you are a developer of"
+
+func (e *AdLibraryAccessError) Error() string {
+	return "this token has no Ad Library API access — " + AdLibraryAccessHint + " (" + e.Err.Error() + ")"
+}
+
+func (e *AdLibraryAccessError) Unwrap() error { return e.Err }
+
+// HTTPError is returned for an HTTP error status that didn't come with a Meta
+// error body.
+type HTTPError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *HTTPError) Error() string {
+	return "HTTP " + itoa(e.StatusCode) + ": " + e.Message
+}
+
+// CursorExpiredError is returned by Search when the paging cursor expired
+// part-way through. Fetched results are discarded, since the set is incomplete.
+type CursorExpiredError struct {
+	// Fetched is how many results had been collected before the cursor expired.
+	Fetched int
+	Err     *MetaError
+}
+
+func (e *CursorExpiredError) Error() string {
+	return "paging cursor expired after " + itoa(e.Fetched) + " result(s), so the result set is incomplete — " +
+		"re-run the search, splitting it into smaller --since/--until date ranges so each run pages through fewer ads (" +
+		e.Err.Error() + ")"
+}
+
+func (e *CursorExpiredError) Unwrap() error { return e.Err }
+
 func itoa(n int) string {
 	if n == 0 {
 		return "0"
@@ -78,14 +176,38 @@ type AdArchiveRecord struct {
 	Bylines                 string          `json:"bylines,omitempty"`
 	// Publisher platforms
 	PublisherPlatforms      []string        `json:"publisher_platforms,omitempty"`
-	// Additional raw data for pass-through
+	// Declared targeting (EU transparency); ages are ["min","max"], e.g. ["25","65+"]
+	TargetAges              []string        `json:"target_ages,omitempty"`
+	TargetGender            string          `json:"target_gender,omitempty"`
+	TargetLocations         []TargetLocation `json:"target_locations,omitempty"`
+	// Delivery breakdowns (EU and political/issue ads), requested by
+	// ad get --history: share of delivery by region, and reach in the EU
+	// in total and by country, age and gender
+	DeliveryByRegion        []Distribution  `json:"delivery_by_region,omitempty"`
+	EUTotalReach            int64           `json:"eu_total_reach,omitempty"`
+	AgeCountryGenderReach   []CountryReach  `json:"age_country_gender_reach_breakdown,omitempty"`
+	// Extra holds, as a JSON object, the keys Meta sent that the fields
+	// above don't model; MarshalJSON writes them back (see extra.go).
 	Extra                   json.RawMessage `json:"-"`
 }
 
+// PermalinkURL is the public Ad Library page of an ad, opened with the ad
+// archive ID appended.
+const PermalinkURL = "https://www.facebook.com/ads/library/?id="
+
+// Permalink returns the ad's public Ad Library URL, which opens in a browser
+// without a token, or "" when the ID is unknown.
+func (a *AdArchiveRecord) Permalink() string {
+	if a.ID == "" {
+		return ""
+	}
+	return PermalinkURL + url.QueryEscape(a.ID)
+}
+
 // RangeValue represents Meta's estimated ranges (spend, impressions).
 type RangeValue struct {
-	LowerBound string `json:"lower_bound"`
-	UpperBound string `json:"upper_bound"`
+	LowerBound string `json:"lower_bound,omitempty"`
+	UpperBound string `json:"upper_bound,omitempty"`
 }
 
 func (r *RangeValue) String() string {
@@ -98,6 +220,50 @@ func (r *RangeValue) String() string {
 	return r.LowerBound + "–" + r.UpperBound
 }
 
+// Lower returns the lower bound as a number, and whether it could be parsed.
+func (r *RangeValue) Lower() (float64, bool) {
+	if r == nil {
+		return 0, false
+	}
+	return parseBound(r.LowerBound)
+}
+
+// Upper returns the upper bound as a number, and whether it could be parsed.
+// Meta omits the upper bound for open-ended ranges (e.g. "1M+").
+func (r *RangeValue) Upper() (float64, bool) {
+	if r == nil {
+		return 0, false
+	}
+	return parseBound(r.UpperBound)
+}
+
+// Midpoint returns the middle of the range. If only one bound parses, that
+// bound is returned; if neither does, 0.
+func (r *RangeValue) Midpoint() float64 {
+	lo, okLo := r.Lower()
+	hi, okHi := r.Upper()
+	switch {
+	case okLo && okHi:
+		return (lo + hi) / 2
+	case okLo:
+		return lo
+	case okHi:
+		return hi
+	}
+	return 0
+}
+
+func parseBound(s string) (float64, bool) {
+	if s == "" {
+		return 0, false
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, false
+	}
+	return f, true
+}
+
 // Distribution represents a percentage breakdown by region.
 type Distribution struct {
 	Region     string  `json:"region"`
@@ -111,6 +277,43 @@ type DemoDistribution struct {
 	Percentage float64 `json:"percentage"`
 }
 
+// CountryReach is the reach of an EU ad in one country, by age range and
+// gender.
+type CountryReach struct {
+	Country             string           `json:"country"`
+	AgeGenderBreakdowns []AgeGenderReach `json:"age_gender_breakdowns"`
+}
+
+// AgeGenderReach is the number of people reached in one age range.
+type AgeGenderReach struct {
+	AgeRange string `json:"age_range"`
+	Male     int64  `json:"male,omitempty"`
+	Female   int64  `json:"female,omitempty"`
+	Unknown  int64  `json:"unknown,omitempty"`
+}
+
+// Total is the reach across genders.
+func (r AgeGenderReach) Total() int64 {
+	return r.Male + r.Female + r.Unknown
+}
+
+// TargetLocation is a location included in (or excluded from) an ad's targeting.
+type TargetLocation struct {
+	Name          string `json:"name"`
+	Type          string `json:"type,omitempty"`
+	Excluded      bool   `json:"excluded,omitempty"`
+	NumObfuscated int    `json:"num_obfuscated,omitempty"`
+}
+
+// Page is a Facebook Page returned by the pages search endpoint.
+type Page struct {
+	ID                 string `json:"id"`
+	Name               string `json:"name"`
+	Category           string `json:"category,omitempty"`
+	VerificationStatus string `json:"verification_status,omitempty"`
+	Link               string `json:"link,omitempty"`
+}
+
 // User is returned by GET /me.
 type User struct {
 	ID    string `json:"id"`
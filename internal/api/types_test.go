@@ -0,0 +1,61 @@
+package api
+
+import "testing"
+
+func TestParseBound(t *testing.T) {
+	tests := []struct {
+		in     string
+		want   float64
+		wantOK bool
+	}{
+		{"", 0, false},
+		{"1000", 1000, true},
+		{"99.5", 99.5, true},
+		{"1M", 0, false},
+		{"n/a", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := parseBound(tt.in)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("parseBound(%q) = %v, %v; want %v, %v", tt.in, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestRangeValue(t *testing.T) {
+	tests := []struct {
+		name  string
+		r     *RangeValue
+		lower float64
+		okLo  bool
+		upper float64
+		okHi  bool
+		mid   float64
+		str   string
+	}{
+		{name: "nil", str: "-"},
+		{name: "empty", r: &RangeValue{}},
+		{name: "closed", r: &RangeValue{LowerBound: "100", UpperBound: "199"}, lower: 100, okLo: true, upper: 199, okHi: true, mid: 149.5, str: "100–199"},
+		{name: "single value", r: &RangeValue{LowerBound: "0", UpperBound: "0"}, okLo: true, okHi: true, str: "0"},
+		{name: "open-ended", r: &RangeValue{LowerBound: "1000000"}, lower: 1000000, okLo: true, mid: 1000000, str: "1000000–"},
+		{name: "upper bound only", r: &RangeValue{UpperBound: "999"}, upper: 999, okHi: true, mid: 999, str: "–999"},
+		{name: "non-numeric bounds", r: &RangeValue{LowerBound: "1K", UpperBound: "5K"}, str: "1K–5K"},
+		{name: "non-numeric upper bound", r: &RangeValue{LowerBound: "500", UpperBound: "more"}, lower: 500, okLo: true, mid: 500, str: "500–more"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if lo, ok := tt.r.Lower(); lo != tt.lower || ok != tt.okLo {
+				t.Errorf("Lower() = %v, %v; want %v, %v", lo, ok, tt.lower, tt.okLo)
+			}
+			if hi, ok := tt.r.Upper(); hi != tt.upper || ok != tt.okHi {
+				t.Errorf("Upper() = %v, %v; want %v, %v", hi, ok, tt.upper, tt.okHi)
+			}
+			if got := tt.r.Midpoint(); got != tt.mid {
+				t.Errorf("Midpoint() = %v; want %v", got, tt.mid)
+			}
+			if got := tt.r.String(); got != tt.str {
+				t.Errorf("String() = %q; want %q", got, tt.str)
+			}
+		})
+	}
+}
@@ -0,0 +1,144 @@
+// Package cache stores data the CLI can reuse between runs, such as the last
+// result set saved with --save-last and fetched exchange rates. Everything
+// here is safe to delete.
+package cache
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Dir returns the cache directory: $XDG_CACHE_HOME/meta-ad-library, falling
+// back to the OS user cache directory.
+func Dir() (string, error) {
+	// As for the config file, XDG_CACHE_HOME wins on every OS.
+	dir := os.Getenv("XDG_CACHE_HOME")
+	if dir == "" {
+		var err error
+		dir, err = os.UserCacheDir()
+		if err != nil {
+			return "", err
+		}
+	}
+	return filepath.Join(dir, "meta-ad-library"), nil
+}
+
+// Last is a saved result set.
+type Last struct {
+	SavedAt time.Time `json:"saved_at"`
+	// Command is the command that produced the results, e.g. "meta-adlib search".
+	Command string            `json:"command"`
+	Items   []json.RawMessage `json:"items"`
+}
+
+// ErrNoLast is returned by LoadLast when nothing has been saved yet.
+var ErrNoLast = errors.New("no saved results — run search or page ads with --save-last first")
+
+// LastPath returns the file holding the last saved result set.
+func LastPath() (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "last.json"), nil
+}
+
+// SaveLast replaces the last saved result set.
+func SaveLast(l *Last) error {
+	path, err := LastPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	data, err := json.Marshal(l)
+	if err != nil {
+		return err
+	}
+	// Write then rename so an interrupted save never leaves a truncated file.
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// LoadLast reads the last saved result set, or returns ErrNoLast.
+func LoadLast() (*Last, error) {
+	path, err := LastPath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNoLast
+	}
+	if err != nil {
+		return nil, err
+	}
+	var l Last
+	if err := json.Unmarshal(data, &l); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	return &l, nil
+}
+
+// Entry is one file in the cache directory.
+type Entry struct {
+	// Name is the file's path relative to Dir.
+	Name    string    `json:"name"`
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"modified"`
+}
+
+// Entries lists the files in the cache directory, subdirectories included,
+// in path order. A missing directory has no entries.
+func Entries() ([]Entry, error) {
+	dir, err := Dir()
+	if err != nil {
+		return nil, err
+	}
+	var entries []Entry
+	err = filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if errors.Is(err, os.ErrNotExist) && path == dir {
+			return filepath.SkipDir
+		}
+		if err != nil || d.IsDir() {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		entries = append(entries, Entry{Name: rel, Size: info.Size(), ModTime: info.ModTime()})
+		return nil
+	})
+	return entries, err
+}
+
+// Clear deletes everything in the cache directory and returns how many files
+// were removed. The directory itself is removed too; it is recreated on the
+// next save.
+func Clear() (int, error) {
+	entries, err := Entries()
+	if err != nil {
+		return 0, err
+	}
+	dir, err := Dir()
+	if err != nil {
+		return 0, err
+	}
+	if err := os.RemoveAll(dir); err != nil {
+		return 0, err
+	}
+	return len(entries), nil
+}
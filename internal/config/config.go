@@ -1,20 +1,61 @@
 package config
 
 import (
-	"encoding/json"
-	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"time"
 )
 
-// Config holds the persisted user configuration.
+// DefaultProfile is the profile used when none has been selected.
+const DefaultProfile = "default"
+
+// Config holds the persisted credentials of a single profile.
 type Config struct {
 	AccessToken    string `json:"access_token"`
 	UserID         string `json:"user_id,omitempty"`
 	UserName       string `json:"user_name,omitempty"`
 	// TokenExpiresAt is a Unix timestamp (seconds). Zero means unknown/never-expires.
 	TokenExpiresAt int64  `json:"token_expires_at,omitempty"`
+	// Scopes are the permissions granted to the token, as reported by /debug_token.
+	Scopes         []string `json:"scopes,omitempty"`
+}
+
+// File is the on-disk layout: a set of named profiles plus the active one,
+// and settings shared by all profiles.
+type File struct {
+	ActiveProfile  string `json:"active_profile,omitempty"`
+	DefaultCountry string `json:"default_country,omitempty"`
+	// DefaultLimit replaces the built-in --limit default when set (0 = all).
+	DefaultLimit *int `json:"default_limit,omitempty"`
+	// Presets maps a search preset name to its saved flags (flag name → values).
+	Presets  map[string]map[string][]string `json:"presets,omitempty"`
+	Profiles map[string]*Config             `json:"profiles,omitempty"`
+}
+
+// legacyFile is the pre-profile layout, where credentials lived at the top level.
+type legacyFile struct {
+	Config
+	File
+}
+
+// pathOverride, when set, replaces the default config file location.
+var pathOverride string
+
+// SetPath redirects all config I/O to path for this process. An empty path
+// falls back to META_ADLIB_CONFIG, then XDG_CONFIG_HOME, then the OS config directory.
+func SetPath(path string) {
+	pathOverride = path
+}
+
+// profileOverride, when set, takes precedence over File.ActiveProfile for this process.
+var profileOverride string
+
+// SetProfile selects the profile used by Load/Save/Clear for this process
+// without changing the persisted active profile. An empty name clears the override.
+func SetProfile(name string) {
+	profileOverride = name
 }
 
 // ExpiresAt returns the expiry time, or zero if unknown.
@@ -46,70 +87,126 @@ func (c *Config) IsExpired() bool {
 	return time.Now().After(time.Unix(c.TokenExpiresAt, 0))
 }
 
-func configPath() (string, error) {
-	dir, err := os.UserConfigDir()
-	if err != nil {
-		return "", err
+// Active returns the name of the profile in effect: the process override,
+// then the persisted active profile, then DefaultProfile.
+func (f *File) Active() string {
+	if profileOverride != "" {
+		return profileOverride
 	}
-	return filepath.Join(dir, "meta-ad-library", "config.json"), nil
+	if f.ActiveProfile != "" {
+		return f.ActiveProfile
+	}
+	return DefaultProfile
 }
 
-// Load reads the config file. Returns an empty Config (not an error) if the file doesn't exist.
-func Load() (*Config, error) {
-	path, err := configPath()
-	if err != nil {
-		return nil, err
+// hasSettings reports whether f holds anything besides profiles worth keeping.
+func (f *File) hasSettings() bool {
+	return f.DefaultCountry != "" || f.DefaultLimit != nil || len(f.Presets) > 0
+}
+
+// Names returns the saved profile names, sorted.
+func (f *File) Names() []string {
+	names := make([]string, 0, len(f.Profiles))
+	for name := range f.Profiles {
+		names = append(names, name)
 	}
+	sort.Strings(names)
+	return names
+}
 
-	data, err := os.ReadFile(path)
-	if err != nil {
-		if errors.Is(err, os.ErrNotExist) {
-			return &Config{}, nil
+func configPath() (string, error) {
+	if pathOverride != "" {
+		return pathOverride, nil
+	}
+	if p := os.Getenv("META_ADLIB_CONFIG"); p != "" {
+		return p, nil
+	}
+	// os.UserConfigDir only honours XDG_CONFIG_HOME on Unix; dotfile setups
+	// on macOS and Windows expect it to win everywhere.
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		var err error
+		dir, err = os.UserConfigDir()
+		if err != nil {
+			return "", err
 		}
-		return nil, err
 	}
+	return filepath.Join(dir, "meta-ad-library", "config.json"), nil
+}
 
-	var cfg Config
-	if err := json.Unmarshal(data, &cfg); err != nil {
+// LoadFile reads the whole config from the active store. Returns an empty File
+// (not an error) if nothing has been saved yet.
+func LoadFile() (*File, error) {
+	return store.Load()
+}
+
+// SaveFile writes the whole config to the active store.
+func SaveFile(f *File) error {
+	return store.Save(f)
+}
+
+// Load returns the active profile. Returns an empty Config (not an error) if it doesn't exist.
+func Load() (*Config, error) {
+	f, err := LoadFile()
+	if err != nil {
 		return nil, err
 	}
-	return &cfg, nil
+	if cfg, ok := f.Profiles[f.Active()]; ok {
+		return cfg, nil
+	}
+	return &Config{}, nil
 }
 
-// Save writes the config file with 0600 permissions.
+// Save stores cfg as the active profile.
 func Save(cfg *Config) error {
-	path, err := configPath()
+	f, err := LoadFile()
 	if err != nil {
 		return err
 	}
+	f.Profiles[f.Active()] = cfg
+	return SaveFile(f)
+}
 
-	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+// Clear removes the active profile (logout).
+func Clear() error {
+	f, err := LoadFile()
+	if err != nil {
 		return err
 	}
+	return RemoveProfile(f.Active())
+}
 
-	data, err := json.MarshalIndent(cfg, "", "  ")
+// RemoveProfile deletes a single named profile. Removing a missing profile is not an error.
+// When the last profile is removed and no settings remain, the stored config itself is deleted.
+func RemoveProfile(name string) error {
+	f, err := LoadFile()
 	if err != nil {
 		return err
 	}
-
-	return os.WriteFile(path, data, 0600)
+	delete(f.Profiles, name)
+	if f.ActiveProfile == name {
+		f.ActiveProfile = ""
+	}
+	if len(f.Profiles) > 0 || f.hasSettings() {
+		return SaveFile(f)
+	}
+	return store.Delete()
 }
 
-// Clear removes the config file (logout).
-func Clear() error {
-	path, err := configPath()
+// Use persists name as the active profile. The profile must already exist.
+func Use(name string) error {
+	f, err := LoadFile()
 	if err != nil {
 		return err
 	}
-	err = os.Remove(path)
-	if errors.Is(err, os.ErrNotExist) {
-		return nil
+	if _, ok := f.Profiles[name]; !ok {
+		return fmt.Errorf("profile %q not found", name)
 	}
-	return err
+	f.ActiveProfile = name
+	return SaveFile(f)
 }
 
-// Path returns the config file path for display purposes.
+// Path returns where the active store keeps the config, for display purposes.
 func Path() string {
-	p, _ := configPath()
-	return p
+	return store.Path()
 }
@@ -0,0 +1,145 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+// Store persists the config File. Load, Save, Clear, and the other package
+// functions all go through the active store, so backends can be swapped
+// without touching callers.
+type Store interface {
+	// Load returns the stored File, or an empty File if nothing is stored yet.
+	Load() (*File, error)
+	// Save replaces the stored File.
+	Save(f *File) error
+	// Delete removes everything stored. Deleting an empty store is not an error.
+	Delete() error
+	// Path describes where the store keeps its data, for display purposes.
+	Path() string
+}
+
+// store is the backend used by the package-level functions.
+var store Store = FileStore{}
+
+// SetStore replaces the backend used by the package-level functions.
+func SetStore(s Store) {
+	store = s
+}
+
+// FileStore keeps the config as JSON in a file: the SetPath override, then
+// META_ADLIB_CONFIG, then XDG_CONFIG_HOME, then the OS config directory.
+type FileStore struct{}
+
+// Load reads the config file. Configs written before profiles existed are
+// migrated into DefaultProfile.
+func (FileStore) Load() (*File, error) {
+	path, err := configPath()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return &File{Profiles: map[string]*Config{}}, nil
+		}
+		return nil, err
+	}
+
+	var raw legacyFile
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	f := raw.File
+	if f.Profiles == nil {
+		f.Profiles = map[string]*Config{}
+	}
+	if raw.AccessToken != "" {
+		if _, ok := f.Profiles[DefaultProfile]; !ok {
+			legacy := raw.Config
+			f.Profiles[DefaultProfile] = &legacy
+		}
+	}
+	return &f, nil
+}
+
+// Save writes the config file with 0600 permissions.
+func (FileStore) Save(f *File) error {
+	path, err := configPath()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, data, 0600)
+}
+
+// Delete removes the config file.
+func (FileStore) Delete() error {
+	path, err := configPath()
+	if err != nil {
+		return err
+	}
+	err = os.Remove(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// Path returns the config file path.
+func (FileStore) Path() string {
+	p, _ := configPath()
+	return p
+}
+
+// MemoryStore keeps the config in memory only. It is meant for tests.
+type MemoryStore struct {
+	file []byte
+}
+
+// Load returns a copy of the stored File.
+func (m *MemoryStore) Load() (*File, error) {
+	f := &File{}
+	if m.file != nil {
+		if err := json.Unmarshal(m.file, f); err != nil {
+			return nil, err
+		}
+	}
+	if f.Profiles == nil {
+		f.Profiles = map[string]*Config{}
+	}
+	return f, nil
+}
+
+// Save stores a copy of f, so later changes by the caller are not persisted.
+func (m *MemoryStore) Save(f *File) error {
+	data, err := json.Marshal(f)
+	if err != nil {
+		return err
+	}
+	m.file = data
+	return nil
+}
+
+// Delete forgets the stored File.
+func (m *MemoryStore) Delete() error {
+	m.file = nil
+	return nil
+}
+
+// Path returns a placeholder, since nothing is written to disk.
+func (m *MemoryStore) Path() string {
+	return "(memory)"
+}
@@ -0,0 +1,223 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// useFile points the FileStore at a fresh file in a temp dir.
+func useFile(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "sub", "config.json")
+	SetPath(path)
+	t.Cleanup(func() { SetPath("") })
+	return path
+}
+
+// useMemory makes the package functions use a fresh MemoryStore.
+func useMemory(t *testing.T) *MemoryStore {
+	t.Helper()
+	m := &MemoryStore{}
+	SetStore(m)
+	t.Cleanup(func() { SetStore(FileStore{}) })
+	return m
+}
+
+func TestFileStoreMissing(t *testing.T) {
+	useFile(t)
+	f, err := FileStore{}.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.Profiles == nil || len(f.Profiles) != 0 {
+		t.Errorf("Profiles = %v; want empty, non-nil map", f.Profiles)
+	}
+	if err := (FileStore{}).Delete(); err != nil {
+		t.Errorf("Delete on missing file: %v", err)
+	}
+}
+
+func TestFileStoreRoundTrip(t *testing.T) {
+	path := useFile(t)
+	limit := 50
+	want := &File{
+		ActiveProfile:  "work",
+		DefaultCountry: "FR",
+		DefaultLimit:   &limit,
+		Presets:        map[string]map[string][]string{"shoes": {"query": {"shoes"}}},
+		Profiles: map[string]*Config{
+			"work": {AccessToken: "tok", UserID: "1", TokenExpiresAt: 1700000000, Scopes: []string{"ads_read"}},
+		},
+	}
+	if err := (FileStore{}).Save(want); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o600 {
+		t.Errorf("file mode = %o; want 600", perm)
+	}
+	got, err := FileStore{}.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Load() = %+v; want %+v", got, want)
+	}
+
+	if err := (FileStore{}).Delete(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("file still exists after Delete: %v", err)
+	}
+}
+
+func TestFileStoreLegacyMigration(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want map[string]*Config
+	}{
+		{
+			name: "top-level token becomes the default profile",
+			data: `{"access_token":"old","user_name":"Ann","default_country":"DE"}`,
+			want: map[string]*Config{DefaultProfile: {AccessToken: "old", UserName: "Ann"}},
+		},
+		{
+			name: "existing default profile wins",
+			data: `{"access_token":"old","profiles":{"default":{"access_token":"new"}}}`,
+			want: map[string]*Config{DefaultProfile: {AccessToken: "new"}},
+		},
+		{
+			name: "other profiles are kept",
+			data: `{"access_token":"old","profiles":{"work":{"access_token":"w"}}}`,
+			want: map[string]*Config{DefaultProfile: {AccessToken: "old"}, "work": {AccessToken: "w"}},
+		},
+		{
+			name: "no top-level token",
+			data: `{"default_country":"DE"}`,
+			want: map[string]*Config{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := useFile(t)
+			if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(path, []byte(tt.data), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			f, err := FileStore{}.Load()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(f.Profiles, tt.want) {
+				t.Errorf("Profiles = %+v; want %+v", f.Profiles, tt.want)
+			}
+		})
+	}
+}
+
+func TestFileStoreInvalidJSON(t *testing.T) {
+	path := useFile(t)
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("{"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := (FileStore{}).Load(); err == nil {
+		t.Error("Load() of malformed file succeeded; want error")
+	}
+}
+
+func TestMemoryStoreCopies(t *testing.T) {
+	m := &MemoryStore{}
+	f := &File{Profiles: map[string]*Config{"a": {AccessToken: "x"}}}
+	if err := m.Save(f); err != nil {
+		t.Fatal(err)
+	}
+	f.Profiles["a"].AccessToken = "changed"
+	got, err := m.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tok := got.Profiles["a"].AccessToken; tok != "x" {
+		t.Errorf("stored token = %q; want %q (caller's later change leaked in)", tok, "x")
+	}
+}
+
+func TestProfiles(t *testing.T) {
+	m := useMemory(t)
+
+	if err := Save(&Config{AccessToken: "d"}); err != nil {
+		t.Fatal(err)
+	}
+	SetProfile("work")
+	t.Cleanup(func() { SetProfile("") })
+	if err := Save(&Config{AccessToken: "w"}); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.AccessToken != "w" {
+		t.Errorf("Load() with --profile work = %q; want %q", cfg.AccessToken, "w")
+	}
+	SetProfile("")
+
+	if err := Use("missing"); err == nil {
+		t.Error("Use of a missing profile succeeded; want error")
+	}
+	if err := Use("work"); err != nil {
+		t.Fatal(err)
+	}
+	f, err := LoadFile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := f.Names(); !reflect.DeepEqual(got, []string{"default", "work"}) {
+		t.Errorf("Names() = %v", got)
+	}
+	if f.Active() != "work" {
+		t.Errorf("Active() = %q; want %q", f.Active(), "work")
+	}
+
+	// Clearing the active profile resets the active name.
+	if err := Clear(); err != nil {
+		t.Fatal(err)
+	}
+	if f, _ = LoadFile(); f.ActiveProfile != "" || len(f.Profiles) != 1 {
+		t.Errorf("after Clear: active %q, profiles %v", f.ActiveProfile, f.Names())
+	}
+
+	// Removing the last profile deletes the store, unless settings remain.
+	f.DefaultCountry = "FR"
+	if err := SaveFile(f); err != nil {
+		t.Fatal(err)
+	}
+	if err := RemoveProfile(DefaultProfile); err != nil {
+		t.Fatal(err)
+	}
+	if m.file == nil {
+		t.Fatal("store deleted although a default country is set")
+	}
+	f.DefaultCountry = ""
+	f.Profiles = map[string]*Config{DefaultProfile: {AccessToken: "d"}}
+	if err := SaveFile(f); err != nil {
+		t.Fatal(err)
+	}
+	if err := RemoveProfile(DefaultProfile); err != nil {
+		t.Fatal(err)
+	}
+	if m.file != nil {
+		t.Errorf("store kept after removing the last profile: %s", m.file)
+	}
+}
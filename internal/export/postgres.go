@@ -0,0 +1,28 @@
+package export
+
+import (
+	"database/sql"
+	"fmt"
+
+	_ "github.com/jackc/pgx/v5/stdlib" // registers "pgx"
+)
+
+// Postgres upserts rows into the ads table of the database at dsn, creating
+// the table if needed. All rows are written in a single transaction.
+func Postgres(dsn string, rows []Row) error {
+	db, err := sql.Open("pgx", dsn)
+	if err != nil {
+		return fmt.Errorf("opening postgres connection: %w", err)
+	}
+	defer db.Close()
+
+	if err := db.Ping(); err != nil {
+		return fmt.Errorf("connecting to postgres: %w", err)
+	}
+
+	if _, err := db.Exec(createTableSQL("JSONB")); err != nil {
+		return fmt.Errorf("creating ads table: %w", err)
+	}
+
+	return upsertRows(db, upsertSQL(func(n int) string { return fmt.Sprintf("$%d", n) }), rows)
+}
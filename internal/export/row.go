@@ -0,0 +1,118 @@
+// Package export persists Ad Library records into local or remote databases.
+//
+// Every backend stores the same flattened Row shape in an "ads" table keyed by
+// the ad archive ID, so repeated exports upsert instead of duplicating.
+package export
+
+import (
+	"database/sql"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/the20100/meta-ad-library-cli/internal/api"
+)
+
+// Row is the storage-ready form of an AdArchiveRecord: scalar fields get their
+// own columns, arrays and distributions are folded into the Data JSON column.
+type Row struct {
+	ID                  string
+	PageID              string
+	PageName            string
+	AdCreationTime      string
+	AdDeliveryStartTime string
+	AdDeliveryStopTime  string
+	AdSnapshotURL       string
+	Currency            string
+	Bylines             string
+	SpendLower          sql.NullInt64
+	SpendUpper          sql.NullInt64
+	ImpressionsLower    sql.NullInt64
+	ImpressionsUpper    sql.NullInt64
+	Data                string
+	SeenAt              string
+}
+
+// rowData is the JSON document stored in the data column.
+type rowData struct {
+	AdCreativeBodies           []string               `json:"ad_creative_bodies,omitempty"`
+	AdCreativeImageURLs        []string               `json:"ad_creative_image_urls,omitempty"`
+	AdCreativeLinkCaptions     []string               `json:"ad_creative_link_captions,omitempty"`
+	AdCreativeLinkDescriptions []string               `json:"ad_creative_link_descriptions,omitempty"`
+	AdCreativeLinkTitles       []string               `json:"ad_creative_link_titles,omitempty"`
+	Languages                  []string               `json:"languages,omitempty"`
+	PublisherPlatforms         []string               `json:"publisher_platforms,omitempty"`
+	RegionDistribution         []api.Distribution     `json:"region_distribution,omitempty"`
+	DemographicDistribution    []api.DemoDistribution `json:"demographic_distribution,omitempty"`
+}
+
+// columns lists the ads table columns in the order Row.args returns them.
+var columns = []string{
+	"id", "page_id", "page_name",
+	"ad_creation_time", "ad_delivery_start_time", "ad_delivery_stop_time",
+	"ad_snapshot_url", "currency", "bylines",
+	"spend_lower", "spend_upper", "impressions_lower", "impressions_upper",
+	"data", "first_seen_at", "last_seen_at",
+}
+
+// NewRow flattens an ad record. seenAt is stamped as both first and last seen;
+// on conflict only last_seen_at is updated.
+func NewRow(a api.AdArchiveRecord, seenAt time.Time) (Row, error) {
+	data, err := json.Marshal(rowData{
+		AdCreativeBodies:           a.AdCreativeBodies,
+		AdCreativeImageURLs:        a.AdCreativeImageURLs,
+		AdCreativeLinkCaptions:     a.AdCreativeLinkCaptions,
+		AdCreativeLinkDescriptions: a.AdCreativeLinkDescriptions,
+		AdCreativeLinkTitles:       a.AdCreativeLinkTitles,
+		Languages:                  a.Languages,
+		PublisherPlatforms:         a.PublisherPlatforms,
+		RegionDistribution:         a.RegionDistribution,
+		DemographicDistribution:    a.DemographicDistribution,
+	})
+	if err != nil {
+		return Row{}, err
+	}
+
+	r := Row{
+		ID:                  a.ID,
+		PageID:              a.PageID,
+		PageName:            a.PageName,
+		AdCreationTime:      a.AdCreationTime,
+		AdDeliveryStartTime: a.AdDeliveryStartTime,
+		AdDeliveryStopTime:  a.AdDeliveryStopTime,
+		AdSnapshotURL:       a.AdSnapshotURL,
+		Currency:            a.Currency,
+		Bylines:             a.Bylines,
+		Data:                string(data),
+		SeenAt:              seenAt.UTC().Format(time.RFC3339),
+	}
+	if a.Spend != nil {
+		r.SpendLower = parseBound(a.Spend.LowerBound)
+		r.SpendUpper = parseBound(a.Spend.UpperBound)
+	}
+	if a.Impressions != nil {
+		r.ImpressionsLower = parseBound(a.Impressions.LowerBound)
+		r.ImpressionsUpper = parseBound(a.Impressions.UpperBound)
+	}
+	return r, nil
+}
+
+// args returns the row's values in columns order.
+func (r Row) args() []any {
+	return []any{
+		r.ID, r.PageID, r.PageName,
+		r.AdCreationTime, r.AdDeliveryStartTime, r.AdDeliveryStopTime,
+		r.AdSnapshotURL, r.Currency, r.Bylines,
+		r.SpendLower, r.SpendUpper, r.ImpressionsLower, r.ImpressionsUpper,
+		r.Data, r.SeenAt, r.SeenAt,
+	}
+}
+
+// parseBound converts a RangeValue bound to a nullable integer.
+func parseBound(s string) sql.NullInt64 {
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return sql.NullInt64{}
+	}
+	return sql.NullInt64{Int64: n, Valid: true}
+}
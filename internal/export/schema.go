@@ -0,0 +1,68 @@
+package export
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// createTableSQL returns the DDL for the ads table. jsonType is the column
+// type used for the data column (TEXT for SQLite, JSONB for Postgres).
+func createTableSQL(jsonType string) string {
+	return `CREATE TABLE IF NOT EXISTS ads (
+	id                     TEXT PRIMARY KEY,
+	page_id                TEXT,
+	page_name              TEXT,
+	ad_creation_time       TEXT,
+	ad_delivery_start_time TEXT,
+	ad_delivery_stop_time  TEXT,
+	ad_snapshot_url        TEXT,
+	currency               TEXT,
+	bylines                TEXT,
+	spend_lower            BIGINT,
+	spend_upper            BIGINT,
+	impressions_lower      BIGINT,
+	impressions_upper      BIGINT,
+	data                   ` + jsonType + `,
+	first_seen_at          TEXT,
+	last_seen_at           TEXT
+)`
+}
+
+// upsertSQL returns an INSERT ... ON CONFLICT(id) DO UPDATE statement that
+// refreshes every column except first_seen_at. placeholder renders the
+// n-th (1-based) bind parameter in the driver's syntax.
+func upsertSQL(placeholder func(n int) string) string {
+	marks := make([]string, len(columns))
+	var updates []string
+	for i, col := range columns {
+		marks[i] = placeholder(i + 1)
+		if col != "id" && col != "first_seen_at" {
+			updates = append(updates, col+" = excluded."+col)
+		}
+	}
+	return fmt.Sprintf("INSERT INTO ads (%s) VALUES (%s) ON CONFLICT (id) DO UPDATE SET %s",
+		strings.Join(columns, ", "), strings.Join(marks, ", "), strings.Join(updates, ", "))
+}
+
+// upsertRows writes rows inside a single transaction using stmt.
+func upsertRows(db *sql.DB, stmt string, rows []Row) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	prepared, err := tx.Prepare(stmt)
+	if err != nil {
+		return fmt.Errorf("preparing upsert: %w", err)
+	}
+	defer prepared.Close()
+
+	for _, r := range rows {
+		if _, err := prepared.Exec(r.args()...); err != nil {
+			return fmt.Errorf("upserting ad %s: %w", r.ID, err)
+		}
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,24 @@
+package export
+
+import (
+	"database/sql"
+	"fmt"
+
+	_ "modernc.org/sqlite" // pure-Go driver, registers "sqlite"
+)
+
+// SQLite upserts rows into the ads table of the SQLite database at path,
+// creating the file and table if needed.
+func SQLite(path string, rows []Row) error {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return fmt.Errorf("opening %s: %w", path, err)
+	}
+	defer db.Close()
+
+	if _, err := db.Exec(createTableSQL("TEXT")); err != nil {
+		return fmt.Errorf("creating ads table: %w", err)
+	}
+
+	return upsertRows(db, upsertSQL(func(int) string { return "?" }), rows)
+}
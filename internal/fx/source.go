@@ -0,0 +1,102 @@
+package fx
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// DefaultURL is the live endpoint used by --fx-source live. Any endpoint
+// returning the Rates format works.
+const DefaultURL = "https://api.frankfurter.app/latest?from=USD"
+
+// Rates is an exchange rate table in the format read from files and live
+// endpoints:
+//
+//	{"base": "USD", "date": "2025-01-10", "rates": {"EUR": 0.96, "GBP": 0.81}}
+//
+// Each rate is how many units of that currency one unit of Base buys. The base
+// currency itself may be omitted from rates.
+type Rates struct {
+	Base  string             `json:"base"`
+	Date  string             `json:"date,omitempty"`
+	Rates map[string]float64 `json:"rates"`
+	// FetchedAt is set on rates cached from a live endpoint.
+	FetchedAt time.Time `json:"fetched_at"`
+}
+
+// Parse decodes and validates a rate table.
+func Parse(data []byte) (*Rates, error) {
+	var r Rates
+	if err := json.Unmarshal(data, &r); err != nil {
+		return nil, err
+	}
+	if r.Base == "" {
+		return nil, errors.New(`missing "base" currency`)
+	}
+	if len(r.Rates) == 0 {
+		return nil, errors.New(`no "rates"`)
+	}
+	table := make(map[string]float64, len(r.Rates)+1)
+	for code, rate := range r.Rates {
+		if rate <= 0 {
+			return nil, fmt.Errorf("rate for %s must be positive, got %v", code, rate)
+		}
+		table[strings.ToUpper(code)] = rate
+	}
+	r.Base = strings.ToUpper(r.Base)
+	table[r.Base] = 1
+	r.Rates = table
+	return &r, nil
+}
+
+// ReadFile reads a rate table from a JSON file.
+func ReadFile(path string) (*Rates, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	r, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	return r, nil
+}
+
+// Fetch downloads a rate table from a live endpoint.
+func Fetch(hc *http.Client, url string) (*Rates, error) {
+	resp, err := hc.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s: HTTP %d", url, resp.StatusCode)
+	}
+	r, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("parsing rates from %s: %w", url, err)
+	}
+	r.FetchedAt = time.Now()
+	return r, nil
+}
+
+// Use replaces the built-in table with r for Known and Convert.
+func Use(r *Rates) {
+	active = r.Rates
+	asOf = r.Date
+}
+
+// AsOf returns the date of the rates in use, if known.
+func AsOf() string {
+	return asOf
+}
@@ -0,0 +1,115 @@
+// Package logging provides the leveled stderr logger shared by the CLI and
+// the API client. Records are written as plain lines ("warning: ...") rather
+// than slog's key=value timestamps, so warnings read the same as always.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// Levels lists the accepted --log-level values, most verbose first.
+var Levels = []string{"debug", "info", "warn", "error"}
+
+// ParseLevel converts a --log-level value to a slog.Level.
+func ParseLevel(s string) (slog.Level, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "info":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	}
+	return 0, fmt.Errorf("unknown log level %q (want %s)", s, strings.Join(Levels, ", "))
+}
+
+// Setup installs a logger writing records at level and above to w as the
+// slog default.
+func Setup(w io.Writer, level slog.Level) {
+	slog.SetDefault(slog.New(&handler{w: w, level: level, mu: &sync.Mutex{}}))
+}
+
+// handler renders records as "<prefix>: message key=value ...".
+type handler struct {
+	w     io.Writer
+	level slog.Level
+	mu    *sync.Mutex
+	attrs []slog.Attr
+}
+
+var prefixes = map[slog.Level]string{
+	slog.LevelDebug: "debug: ",
+	slog.LevelInfo:  "note: ",
+	slog.LevelWarn:  "warning: ",
+	slog.LevelError: "error: ",
+}
+
+func (h *handler) Enabled(_ context.Context, l slog.Level) bool {
+	return l >= h.level
+}
+
+func (h *handler) Handle(_ context.Context, r slog.Record) error {
+	var b strings.Builder
+	b.WriteString(prefixes[r.Level])
+	b.WriteString(r.Message)
+	write := func(a slog.Attr) bool {
+		if a.Equal(slog.Attr{}) {
+			return true
+		}
+		v := a.Value.Resolve().String()
+		if v == "" || strings.ContainsAny(v, " \t\"=") {
+			v = fmt.Sprintf("%q", v)
+		}
+		fmt.Fprintf(&b, " %s=%s", a.Key, v)
+		return true
+	}
+	for _, a := range h.attrs {
+		write(a)
+	}
+	r.Attrs(write)
+	b.WriteByte('\n')
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, err := io.WriteString(h.w, b.String())
+	return err
+}
+
+func (h *handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	c := *h
+	c.attrs = append(append([]slog.Attr{}, h.attrs...), attrs...)
+	return &c
+}
+
+// WithGroup is not used by the CLI; groups are flattened.
+func (h *handler) WithGroup(string) slog.Handler {
+	return h
+}
+
+// secretParams are query parameters redacted by RedactURL.
+var secretParams = []string{"access_token", "client_secret", "fb_exchange_token", "input_token"}
+
+// RedactURL returns raw with secret query parameters replaced by REDACTED, for
+// logging request URLs. Unparseable URLs are returned as "(unparseable URL)".
+func RedactURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "(unparseable URL)"
+	}
+	q := u.Query()
+	for _, p := range secretParams {
+		if q.Has(p) {
+			q.Set(p, "REDACTED")
+		}
+	}
+	u.RawQuery = q.Encode()
+	return u.String()
+}
@@ -1,5 +1,6 @@
 // Package metaauth reads the shared token managed by meta-auth-cli.
-// Config path: ~/.config/meta-auth/config.json
+// Config path: $XDG_CONFIG_HOME/meta-auth/config.json if XDG_CONFIG_HOME is set,
+// otherwise meta-auth/config.json under the OS config directory.
 //
 // Token resolution order used by each CLI:
 //  1. META_TOKEN env var
@@ -21,13 +22,27 @@ type sharedConfig struct {
 	TokenExpiresAt int64  `json:"token_expires_at,omitempty"`
 }
 
+// Path returns the location of the shared meta-auth config. XDG_CONFIG_HOME is
+// honoured on every OS, including macOS and Windows where os.UserConfigDir
+// ignores it.
+func Path() (string, error) {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		var err error
+		dir, err = os.UserConfigDir()
+		if err != nil {
+			return "", err
+		}
+	}
+	return filepath.Join(dir, "meta-auth", "config.json"), nil
+}
+
 // Token returns the token stored by meta-auth-cli, or ("", nil) if not found.
 func Token() (string, error) {
-	dir, err := os.UserConfigDir()
+	path, err := Path()
 	if err != nil {
 		return "", err
 	}
-	path := filepath.Join(dir, "meta-auth", "config.json")
 
 	data, err := os.ReadFile(path)
 	if err != nil {
@@ -46,8 +61,7 @@ func Token() (string, error) {
 
 // IsExpired reports whether the shared token has a known expiry that has passed.
 func IsExpired() bool {
-	dir, _ := os.UserConfigDir()
-	path := filepath.Join(dir, "meta-auth", "config.json")
+	path, _ := Path()
 	data, err := os.ReadFile(path)
 	if err != nil {
 		return false
@@ -64,8 +78,7 @@ func IsExpired() bool {
 
 // DaysUntilExpiry returns days until the shared token expires, -1 if unknown.
 func DaysUntilExpiry() int {
-	dir, _ := os.UserConfigDir()
-	path := filepath.Join(dir, "meta-auth", "config.json")
+	path, _ := Path()
 	data, err := os.ReadFile(path)
 	if err != nil {
 		return -1
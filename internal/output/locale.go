@@ -0,0 +1,117 @@
+package output
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// Locale holds the number and date conventions of one locale.
+type Locale struct {
+	// Group separates thousands in numbers, e.g. "," in 1,234.
+	Group string
+	// Decimal separates the fractional part of numbers.
+	Decimal string
+	// Date is the time.Format layout for dates.
+	Date string
+}
+
+// locales are the locales --locale accepts, keyed by lowercase tag.
+var locales = map[string]Locale{
+	"en-us": {",", ".", "01/02/2006"},
+	"en-gb": {",", ".", "02/01/2006"},
+	"de-de": {".", ",", "02.01.2006"},
+	"de-at": {".", ",", "02.01.2006"},
+	"de-ch": {"’", ".", "02.01.2006"},
+	"fr-fr": {" ", ",", "02/01/2006"},
+	"es-es": {".", ",", "02/01/2006"},
+	"it-it": {".", ",", "02/01/2006"},
+	"nl-nl": {".", ",", "02-01-2006"},
+	"pt-br": {".", ",", "02/01/2006"},
+	"pl-pl": {" ", ",", "02.01.2006"},
+	"sv-se": {" ", ",", "2006-01-02"},
+	"ja-jp": {",", ".", "2006/01/02"},
+}
+
+// active is the locale set by SetLocale; nil keeps the default ISO dates and
+// unseparated numbers.
+var active *Locale
+
+// Locales returns the tags SetLocale accepts, sorted.
+func Locales() []string {
+	tags := make([]string, 0, len(locales))
+	for tag := range locales {
+		lang, region, _ := strings.Cut(tag, "-")
+		tags = append(tags, lang+"-"+strings.ToUpper(region))
+	}
+	sort.Strings(tags)
+	return tags
+}
+
+// SetLocale makes FormatTime and FormatNumber follow the conventions of tag
+// (e.g. "de-DE"; "de_DE" works too). An empty tag restores the defaults. It
+// reports whether the tag is known.
+func SetLocale(tag string) bool {
+	if tag == "" {
+		active = nil
+		return true
+	}
+	l, ok := locales[strings.ToLower(strings.ReplaceAll(tag, "_", "-"))]
+	if !ok {
+		return false
+	}
+	active = &l
+	return true
+}
+
+// FormatNumber adds the locale's thousands separators to a decimal number such
+// as "1234" or "1234.5". Other strings, and every string when no locale is
+// set, are returned unchanged.
+func FormatNumber(s string) string {
+	if active == nil || s == "" {
+		return s
+	}
+	intPart, frac, hasFrac := strings.Cut(s, ".")
+	sign := ""
+	if strings.HasPrefix(intPart, "-") {
+		sign, intPart = "-", intPart[1:]
+	}
+	if intPart == "" || !allDigits(intPart) || (hasFrac && !allDigits(frac)) {
+		return s
+	}
+	var b strings.Builder
+	b.WriteString(sign)
+	for i, r := range intPart {
+		if i > 0 && (len(intPart)-i)%3 == 0 {
+			b.WriteString(active.Group)
+		}
+		b.WriteRune(r)
+	}
+	if hasFrac {
+		b.WriteString(active.Decimal)
+		b.WriteString(frac)
+	}
+	return b.String()
+}
+
+func allDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// localDate rewrites the YYYY-MM-DD date at the start of t in the locale's
+// date layout, keeping the rest of t.
+func localDate(t string) string {
+	if active == nil || len(t) < 10 {
+		return t
+	}
+	d, err := time.Parse("2006-01-02", t[:10])
+	if err != nil {
+		return t
+	}
+	return d.Format(active.Date) + t[10:]
+}
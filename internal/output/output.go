@@ -1,14 +1,27 @@
 package output
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
+	"sort"
 	"strings"
 	"text/tabwriter"
+	"unicode/utf8"
 
 	"github.com/mattn/go-isatty"
 	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+// Out and Err are where output is written. They default to the process's
+// stdout and stderr; tests swap them to capture output. TTY detection always
+// looks at the real stdout.
+var (
+	Out io.Writer = os.Stdout
+	Err io.Writer = os.Stderr
 )
 
 // IsJSON returns true when output should be JSON:
@@ -35,42 +48,212 @@ func IsPretty(cmd *cobra.Command) bool {
 	return p
 }
 
-// PrintJSON encodes v as JSON to stdout.
+// IsTerminal reports whether stdout is an interactive terminal.
+func IsTerminal() bool {
+	return isatty.IsTerminal(os.Stdout.Fd()) || isatty.IsCygwinTerminal(os.Stdout.Fd())
+}
+
+// Select, when non-empty, projects the JSON printed by PrintJSON down to these
+// top-level keys, in this order (--select): the object itself, or each object
+// of an array. Keys an object lacks are left out.
+var Select []string
+
+// PrintJSON encodes v as JSON to Out, projected to Select first.
 func PrintJSON(v any, pretty bool) error {
-	enc := json.NewEncoder(os.Stdout)
+	if len(Select) > 0 {
+		var err error
+		if v, err = project(v); err != nil {
+			return err
+		}
+	}
+	enc := json.NewEncoder(Out)
 	if pretty {
 		enc.SetIndent("", "  ")
 	}
 	return enc.Encode(v)
 }
 
-// PrintTable writes a tab-aligned table to stdout.
+// project applies Select to v: to each element when v encodes as an array,
+// else to v itself. Values other than raw JSON are encoded first.
+func project(v any) (any, error) {
+	items, ok := v.([]json.RawMessage)
+	if !ok {
+		raw, isRaw := v.(json.RawMessage)
+		if !isRaw {
+			var err error
+			if raw, err = json.Marshal(v); err != nil {
+				return nil, err
+			}
+		}
+		if json.Unmarshal(raw, &items) != nil {
+			return projectObject(raw)
+		}
+	}
+	out := make([]json.RawMessage, len(items))
+	for i, item := range items {
+		p, err := projectObject(item)
+		if err != nil {
+			return nil, err
+		}
+		out[i] = p
+	}
+	return out, nil
+}
+
+// projectObject keeps the Select keys of a JSON object. Non-objects are
+// returned unchanged.
+func projectObject(raw json.RawMessage) (json.RawMessage, error) {
+	var obj map[string]json.RawMessage
+	if json.Unmarshal(raw, &obj) != nil {
+		return raw, nil
+	}
+	var b bytes.Buffer
+	b.WriteByte('{')
+	for _, key := range Select {
+		val, ok := obj[key]
+		if !ok {
+			continue
+		}
+		if b.Len() > 1 {
+			b.WriteByte(',')
+		}
+		k, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		b.Write(k)
+		b.WriteByte(':')
+		b.Write(val)
+	}
+	b.WriteByte('}')
+	return b.Bytes(), nil
+}
+
+// NoColor disables terminal escape sequences (hyperlinks) even on a TTY.
+var NoColor bool
+
+// Width caps the width of tables, in cells (--width). 0 means the terminal's
+// width when stdout is a terminal, and no cap otherwise.
+var Width int
+
+// minColumnWidth is the narrowest a column is squeezed to when fitting a
+// table into Width.
+const minColumnWidth = 4
+
+// PrintTable writes an aligned table to Out. Column widths are computed on
+// the visible text, so cells may contain Hyperlink escapes. When the table is
+// wider than Width (or the terminal), the widest columns are narrowed and
+// their cells truncated with "…" so each row stays on one line.
 func PrintTable(headers []string, rows [][]string) {
-	w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
-	defer w.Flush()
+	all := append([][]string{headers}, rows...)
 
-	for i, h := range headers {
-		if i > 0 {
-			fmt.Fprint(w, "\t")
+	var widths []int
+	for _, row := range all {
+		for i, cell := range row {
+			if i >= len(widths) {
+				widths = append(widths, 0)
+			}
+			if n := displayWidth(cell); n > widths[i] {
+				widths[i] = n
+			}
 		}
-		fmt.Fprint(w, h)
 	}
-	fmt.Fprintln(w)
+	widths = fitWidths(widths, 2, tableWidth())
 
-	for _, row := range rows {
+	var b strings.Builder
+	for _, row := range all {
 		for i, cell := range row {
-			if i > 0 {
-				fmt.Fprint(w, "\t")
+			cell = fitCell(cell, widths[i])
+			b.WriteString(cell)
+			if i < len(row)-1 {
+				b.WriteString(strings.Repeat(" ", widths[i]-displayWidth(cell)+2))
+			}
+		}
+		b.WriteByte('\n')
+	}
+	fmt.Fprint(Out, b.String())
+}
+
+// tableWidth returns the width tables must fit in, or 0 for no limit.
+func tableWidth() int {
+	if Width > 0 {
+		return Width
+	}
+	if !IsTerminal() {
+		return 0
+	}
+	if w, _, err := term.GetSize(int(os.Stdout.Fd())); err == nil && w > 0 {
+		return w
+	}
+	return 0
+}
+
+// fitWidths returns column widths that fit in limit cells, gap being the
+// spacing between columns. Columns narrower than an equal share of the space
+// keep their width; the wider ones split what is left. No column goes below
+// minColumnWidth, so very narrow limits can still overflow.
+func fitWidths(widths []int, gap, limit int) []int {
+	avail := limit - gap*(len(widths)-1)
+	total := 0
+	for _, w := range widths {
+		total += w
+	}
+	if limit <= 0 || total <= avail {
+		return widths
+	}
+
+	order := make([]int, len(widths))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return widths[order[a]] < widths[order[b]] })
+
+	out := append([]int(nil), widths...)
+	for k, i := range order {
+		share := avail / (len(order) - k)
+		if widths[i] <= share {
+			avail -= widths[i]
+			continue
+		}
+		// This column and every wider one get an equal share; the first
+		// few take the remainder.
+		rest := order[k:]
+		for j, c := range rest {
+			out[c] = max(avail/len(rest), minColumnWidth)
+			if j < avail%len(rest) {
+				out[c]++
 			}
-			fmt.Fprint(w, cell)
 		}
-		fmt.Fprintln(w)
+		break
+	}
+	return out
+}
+
+// fitCell truncates cell to width visible cells, keeping a Hyperlink escape
+// around the shortened text.
+func fitCell(cell string, width int) string {
+	if displayWidth(cell) <= width {
+		return cell
+	}
+	const open, end = "\x1b]8;;", "\x1b\\"
+	if strings.HasPrefix(cell, open) {
+		if i := strings.Index(cell, end); i >= 0 {
+			url := cell[len(open):i]
+			text := strings.TrimSuffix(cell[i+len(end):], open+end)
+			return open + url + end + Truncate(text, width) + open + end
+		}
 	}
+	return Truncate(cell, width)
 }
 
-// PrintKeyValue prints a two-column key-value table.
+// PrintKeyValue prints a two-column key-value table to Out.
 func PrintKeyValue(rows [][]string) {
-	w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	FprintKeyValue(Out, rows)
+}
+
+// FprintKeyValue writes a two-column key-value table to out.
+func FprintKeyValue(out io.Writer, rows [][]string) {
+	w := tabwriter.NewWriter(out, 0, 8, 2, ' ', 0)
 	defer w.Flush()
 	for _, row := range rows {
 		if len(row) == 2 && row[1] != "" && row[1] != "-" {
@@ -79,9 +262,59 @@ func PrintKeyValue(rows [][]string) {
 	}
 }
 
-// PrintError prints an error message to stderr.
+// Hyperlink wraps text in an OSC 8 escape linking to url, so supporting
+// terminals render it clickable. It returns text unchanged when stdout is not a
+// TTY, NoColor is set, or url is empty.
+func Hyperlink(url, text string) string {
+	if url == "" || !linksEnabled() {
+		return text
+	}
+	return "\x1b]8;;" + url + "\x1b\\" + text + "\x1b]8;;\x1b\\"
+}
+
+func linksEnabled() bool {
+	if NoColor || os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	return isatty.IsTerminal(os.Stdout.Fd())
+}
+
+// displayWidth returns the number of visible runes in s, ignoring OSC 8 escapes.
+func displayWidth(s string) int {
+	n := 0
+	for len(s) > 0 {
+		if strings.HasPrefix(s, "\x1b]8;;") {
+			end := strings.Index(s, "\x1b\\")
+			if end < 0 {
+				break
+			}
+			s = s[end+2:]
+			continue
+		}
+		_, size := utf8.DecodeRuneInString(s)
+		s = s[size:]
+		n++
+	}
+	return n
+}
+
+// Bar renders value as a horizontal bar of unicode blocks, width cells long
+// when value equals max. Eighth-blocks give sub-cell precision.
+func Bar(value, max float64, width int) string {
+	if max <= 0 || value <= 0 {
+		return ""
+	}
+	eighths := int(value / max * float64(width*8))
+	if eighths == 0 {
+		eighths = 1
+	}
+	partials := []string{"", "▏", "▎", "▍", "▌", "▋", "▊", "▉"}
+	return strings.Repeat("█", eighths/8) + partials[eighths%8]
+}
+
+// PrintError prints an error message to Err.
 func PrintError(err error) {
-	fmt.Fprintf(os.Stderr, "error: %s\n", err.Error())
+	fmt.Fprintf(Err, "error: %s\n", err.Error())
 }
 
 // Truncate shortens a string to maxLen characters, adding "…" if truncated.
@@ -93,15 +326,16 @@ func Truncate(s string, maxLen int) string {
 	return string(runes[:maxLen-1]) + "…"
 }
 
-// FormatTime trims Meta's ISO-8601 timestamps to a shorter form.
+// FormatTime trims Meta's ISO-8601 timestamps to a shorter form, with the
+// date in the layout of the locale set by SetLocale.
 func FormatTime(t string) string {
 	if t == "" {
 		return "-"
 	}
 	if len(t) >= 16 {
-		return t[:10] + " " + t[11:16]
+		return localDate(t[:10]) + " " + t[11:16]
 	}
-	return t
+	return localDate(t)
 }
 
 // JoinStrings joins a slice with a separator, returning "-" for empty slices.
@@ -0,0 +1,272 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+// capture redirects Out and Err to buffers for the duration of a test.
+func capture(t *testing.T) (out, errOut *bytes.Buffer) {
+	t.Helper()
+	out, errOut = &bytes.Buffer{}, &bytes.Buffer{}
+	prevOut, prevErr := Out, Err
+	Out, Err = out, errOut
+	t.Cleanup(func() { Out, Err = prevOut, prevErr })
+	return out, errOut
+}
+
+func TestPrintTable(t *testing.T) {
+	tests := []struct {
+		name    string
+		headers []string
+		rows    [][]string
+		width   int
+		want    string
+	}{
+		{
+			name:    "headers only",
+			headers: []string{"ID", "NAME"},
+			want:    "ID  NAME\n",
+		},
+		{
+			name:    "pads to widest cell, last column unpadded",
+			headers: []string{"ID", "NAME"},
+			rows:    [][]string{{"12345", "a"}, {"1", "longer"}},
+			want:    "ID     NAME\n12345  a\n1      longer\n",
+		},
+		{
+			name:    "counts runes, not bytes",
+			headers: []string{"A", "B"},
+			rows:    [][]string{{"héllo", "x"}},
+			want:    "A      B\nhéllo  x\n",
+		},
+		{
+			name:    "ignores hyperlink escapes when measuring",
+			headers: []string{"ID", "X"},
+			rows:    [][]string{{"\x1b]8;;https://e.x\x1b\\42\x1b]8;;\x1b\\", "y"}},
+			want:    "ID  X\n\x1b]8;;https://e.x\x1b\\42\x1b]8;;\x1b\\  y\n",
+		},
+		{
+			name:    "narrows the widest column to fit the width",
+			headers: []string{"ID", "BODY"},
+			rows:    [][]string{{"1", "a rather long creative body"}},
+			width:   16,
+			want:    "ID  BODY\n1   a rather lo…\n",
+		},
+		{
+			name:    "keeps the hyperlink around a truncated cell",
+			headers: []string{"ID", "X"},
+			rows:    [][]string{{"\x1b]8;;https://e.x\x1b\\123456789\x1b]8;;\x1b\\", "y"}},
+			width:   9,
+			want:    "ID      X\n\x1b]8;;https://e.x\x1b\\12345…\x1b]8;;\x1b\\  y\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, _ := capture(t)
+			prev := Width
+			Width = tt.width
+			t.Cleanup(func() { Width = prev })
+			PrintTable(tt.headers, tt.rows)
+			if got := out.String(); got != tt.want {
+				t.Errorf("PrintTable() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrintJSONSelect(t *testing.T) {
+	tests := []struct {
+		name       string
+		v          any
+		selectKeys []string
+		want       string
+	}{
+		{
+			name: "no selection prints as-is",
+			v:    []json.RawMessage{json.RawMessage(`{"id":"1","spend":{"lower_bound":"0"}}`)},
+			want: `[{"id":"1","spend":{"lower_bound":"0"}}]` + "\n",
+		},
+		{
+			name:       "keeps selected keys in selection order",
+			v:          []json.RawMessage{json.RawMessage(`{"page_name":"P","id":"1","spend":{"lower_bound":"0"}}`)},
+			selectKeys: []string{"spend", "id"},
+			want:       `[{"spend":{"lower_bound":"0"},"id":"1"}]` + "\n",
+		},
+		{
+			name:       "omits missing keys",
+			v:          json.RawMessage(`{"id":"1"}`),
+			selectKeys: []string{"id", "spend"},
+			want:       `{"id":"1"}` + "\n",
+		},
+		{
+			name:       "projects encoded values too",
+			v:          map[string]int{"a": 1, "b": 2},
+			selectKeys: []string{"a"},
+			want:       `{"a":1}` + "\n",
+		},
+		{
+			name:       "projects each object of an encoded array",
+			v:          []struct{ ID, Name string }{{"1", "a"}, {"2", "b"}},
+			selectKeys: []string{"Name"},
+			want:       `[{"Name":"a"},{"Name":"b"}]` + "\n",
+		},
+		{
+			name:       "projects each object of a raw array",
+			v:          json.RawMessage(`[{"id":"1","x":1},2]`),
+			selectKeys: []string{"id"},
+			want:       `[{"id":"1"},2]` + "\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, _ := capture(t)
+			prev := Select
+			Select = tt.selectKeys
+			t.Cleanup(func() { Select = prev })
+			if err := PrintJSON(tt.v, false); err != nil {
+				t.Fatal(err)
+			}
+			if got := out.String(); got != tt.want {
+				t.Errorf("PrintJSON() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrintKeyValue(t *testing.T) {
+	tests := []struct {
+		name string
+		rows [][]string
+		want string
+	}{
+		{
+			name: "aligns values",
+			rows: [][]string{{"ID", "1"}, {"Platforms", "facebook"}},
+			want: "ID         1\nPlatforms  facebook\n",
+		},
+		{
+			name: "skips empty and dash values",
+			rows: [][]string{{"ID", "1"}, {"Stopped", "-"}, {"Bylines", ""}},
+			want: "ID  1\n",
+		},
+		{
+			name: "skips malformed rows",
+			rows: [][]string{{"ID"}, {"Page", "Acme", "extra"}},
+			want: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, _ := capture(t)
+			PrintKeyValue(tt.rows)
+			if got := out.String(); got != tt.want {
+				t.Errorf("PrintKeyValue() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrintErrorWritesToErr(t *testing.T) {
+	out, errOut := capture(t)
+	PrintError(errors.New("boom"))
+	if out.Len() != 0 {
+		t.Errorf("PrintError wrote to Out: %q", out.String())
+	}
+	if got, want := errOut.String(), "error: boom\n"; got != want {
+		t.Errorf("PrintError() = %q, want %q", got, want)
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		in     string
+		maxLen int
+		want   string
+	}{
+		{"short", 10, "short"},
+		{"exact", 5, "exact"},
+		{"toolong", 5, "tool…"},
+		{"héllo wörld", 6, "héllo…"},
+		{"ab", 1, "…"},
+		{"", 3, ""},
+	}
+	for _, tt := range tests {
+		if got := Truncate(tt.in, tt.maxLen); got != tt.want {
+			t.Errorf("Truncate(%q, %d) = %q, want %q", tt.in, tt.maxLen, got, tt.want)
+		}
+	}
+}
+
+func TestFormatTime(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"", "-"},
+		{"2024-03-01T14:05:09+0000", "2024-03-01 14:05"},
+		{"2024-03-01T14:05", "2024-03-01 14:05"},
+		{"2024-03-01", "2024-03-01"},
+	}
+	for _, tt := range tests {
+		if got := FormatTime(tt.in); got != tt.want {
+			t.Errorf("FormatTime(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestJoinStrings(t *testing.T) {
+	tests := []struct {
+		in   []string
+		sep  string
+		want string
+	}{
+		{nil, ", ", "-"},
+		{[]string{}, ", ", "-"},
+		{[]string{"facebook"}, ", ", "facebook"},
+		{[]string{"facebook", "instagram"}, ", ", "facebook, instagram"},
+		{[]string{"a", "b"}, "|", "a|b"},
+	}
+	for _, tt := range tests {
+		if got := JoinStrings(tt.in, tt.sep); got != tt.want {
+			t.Errorf("JoinStrings(%q, %q) = %q, want %q", tt.in, tt.sep, got, tt.want)
+		}
+	}
+}
+
+func TestLocale(t *testing.T) {
+	if SetLocale("xx-XX") {
+		t.Fatal("SetLocale accepted an unknown tag")
+	}
+	if !SetLocale("de_DE") {
+		t.Fatal("SetLocale rejected de_DE")
+	}
+	defer SetLocale("")
+
+	numbers := []struct{ in, want string }{
+		{"1234", "1.234"},
+		{"999", "999"},
+		{"1000000", "1.000.000"},
+		{"-12345.5", "-12.345,5"},
+		{"1M+", "1M+"},
+		{"", ""},
+	}
+	for _, tt := range numbers {
+		if got := FormatNumber(tt.in); got != tt.want {
+			t.Errorf("FormatNumber(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+	if got, want := FormatTime("2024-03-01T14:05:09+0000"), "01.03.2024 14:05"; got != want {
+		t.Errorf("FormatTime = %q, want %q", got, want)
+	}
+	if got, want := FormatTime("2024-03-01"), "01.03.2024"; got != want {
+		t.Errorf("FormatTime = %q, want %q", got, want)
+	}
+
+	SetLocale("")
+	if got := FormatNumber("1234"); got != "1234" {
+		t.Errorf("FormatNumber without a locale = %q, want 1234", got)
+	}
+}